@@ -0,0 +1,213 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+)
+
+var errUnknownCommand = errors.New("Command not recognized")
+
+// command is one thing that can be typed at the "Pokedex > " prompt
+type command struct {
+	name string
+	aliases []string
+	args string  // Argument synopsis for help and usage errors, e.g. "<pokemon> [nickname]"
+	description string
+	minArgs int
+	maxArgs int  // -1 for no limit
+	handler func(ctx context.Context, s *session, args []string) error
+	complete func(s *session, argIndex int) []string  // Tab completion candidates for the argument at argIndex, may be nil
+}
+
+type commandRegistry struct {
+	commands []*command
+	byName map[string]*command
+}
+
+// usageError is returned when a command gets the wrong arguments. Handlers can leave command out, run fills it in.
+type usageError struct {
+	command *command
+	problem string  // What was wrong, if there's more to say than the usage
+}
+
+/*-------------------------------------------------------------------------------------------------------------------------------------------------------------------*/
+
+func (e *usageError) Error() (message string) {
+	if e.problem != "" {
+		return e.problem + "\nUsage: " + e.command.synopsis()
+	}
+
+	return "Usage: " + e.command.synopsis()
+}
+
+func (c *command) synopsis() (synopsis string) {
+	if c.args == "" {
+		return c.name
+	}
+
+	return c.name + " " + c.args
+}
+
+// register panics on a name or alias that is already taken, since that can only be a programming mistake
+func (r *commandRegistry) register(c command) {
+	registered := &c
+	for _, name := range append([]string{c.name}, c.aliases...) {
+		if _, ok := r.byName[name]; ok {
+			panic(fmt.Sprintf("command %q registered twice", name))
+		}
+		r.byName[name] = registered
+	}
+
+	r.commands = append(r.commands, registered)
+	return
+}
+
+func (r *commandRegistry) find(name string) (c *command, isFound bool) {
+	c, isFound = r.byName[name]
+	return c, isFound
+}
+
+// run tokenizes one line of input and hands it to the matching command. An empty line does nothing.
+func (r *commandRegistry) run(ctx context.Context, s *session, line string) (err error) {
+	words, err := tokenize(line)
+	if err != nil {
+		return err
+	} else if len(words) == 0 {
+		return nil
+	}
+
+	return r.runWords(ctx, s, words)
+}
+
+// runWords runs the command named by the first word with the rest as its arguments
+func (r *commandRegistry) runWords(ctx context.Context, s *session, words []string) (err error) {
+	c, ok := r.find(words[0])
+	if !ok {
+		return errUnknownCommand
+	}
+
+	args := words[1:]
+	if len(args) < c.minArgs || (c.maxArgs >= 0 && len(args) > c.maxArgs) {
+		return &usageError{command: c}
+	}
+
+	err = c.handler(ctx, s, args)
+	var usageErr *usageError
+	if errors.As(err, &usageErr) && usageErr.command == nil {
+		usageErr.command = c
+	}
+	return err
+}
+
+// completions is the editor's tab completion: command names for the first word, and whatever the command offers after that
+func (r *commandRegistry) completions(s *session, words []string, partial string) (candidates []string) {
+	if len(words) == 0 {
+		for name := range r.byName {
+			candidates = append(candidates, name)
+		}
+		slices.Sort(candidates)
+		return candidates
+	}
+
+	c, ok := r.find(words[0])
+	if !ok || c.complete == nil {
+		return nil
+	}
+
+	return c.complete(s, len(words) - 1)
+}
+
+func (r *commandRegistry) help() (help helpResult) {
+	help.Commands = []commandSummary{}
+	for _, c := range r.commands {
+		help.Commands = append(help.Commands, commandSummary{Name: c.name, Aliases: nonNil(c.aliases), Args: c.args, Description: c.description})
+	}
+
+	return help
+}
+
+/*-------------------------------------------------------------------------------------------------------------------------------------------------------------------*/
+
+// tokenize splits a line into words on runs of whitespace. Single quotes keep everything inside them literally,
+// and a backslash inside double quotes or outside any quotes escapes the next character.
+func tokenize(line string) (words []string, err error) {
+	var word strings.Builder
+	inWord := false
+	var quote rune
+	escaped := false
+
+	for _, char := range line {
+		switch {
+		case escaped:
+			word.WriteRune(char)
+			escaped = false
+		case quote == '\'' && char == '\'':
+			quote = 0
+		case quote == '\'':
+			word.WriteRune(char)
+		case char == '\\' && (quote == 0 || quote == '"'):
+			escaped = true
+			inWord = true
+		case quote == '"' && char == '"':
+			quote = 0
+		case quote == '"':
+			word.WriteRune(char)
+		case char == '\'' || char == '"':
+			quote = char
+			inWord = true
+		case char == ' ' || char == '\t' || char == '\n' || char == '\r':
+			if inWord {
+				words = append(words, word.String())
+				word.Reset()
+				inWord = false
+			}
+		default:
+			word.WriteRune(char)
+			inWord = true
+		}
+	}
+
+	if quote != 0 {
+		return nil, fmt.Errorf("Missing closing %c quote", quote)
+	} else if escaped {
+		return nil, errors.New("Nothing to escape after the final \\")
+	}
+
+	if inWord {
+		words = append(words, word.String())
+	}
+	return words, nil
+}
+
+// parseFlags parses flags wherever they are among a command's arguments, so "catch pikachu --odds" works as well as
+// "catch --odds pikachu", and returns the arguments that aren't flags. Everything after a "--" is left alone.
+func parseFlags(flags *flag.FlagSet, args []string) (positional []string, err error) {
+	flags.SetOutput(io.Discard)
+	for {
+		if err = flags.Parse(args); err != nil {
+			return nil, &usageError{problem: err.Error()}
+		}
+
+		parsed := len(args) - flags.NArg()
+		if parsed > 0 && args[parsed - 1] == "--" {
+			return append(positional, flags.Args()...), nil
+		} else if flags.NArg() == 0 {
+			return positional, nil
+		}
+
+		positional = append(positional, flags.Arg(0))
+		args = flags.Args()[1:]
+	}
+}
+
+/*==================================================================================================================================*/
+
+func newCommandRegistry() (registry *commandRegistry) {
+	registry = &commandRegistry{byName: make(map[string]*command)}
+	return registry
+}
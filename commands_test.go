@@ -0,0 +1,50 @@
+package main
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestTokenize(t *testing.T) {
+	tests := []struct {
+		line string
+		want []string
+		wantErr string
+	}{
+		{"", nil, ""},
+		{"   \t ", nil, ""},
+		{"map", []string{"map"}, ""},
+		{"  catch   pikachu\t--odds ", []string{"catch", "pikachu", "--odds"}, ""},
+		{`nickname 12 "Sir Bubbles"`, []string{"nickname", "12", "Sir Bubbles"}, ""},
+		{`nickname 12 'Sir Bubbles'`, []string{"nickname", "12", "Sir Bubbles"}, ""},
+		{`nickname 12 Sir\ Bubbles`, []string{"nickname", "12", "Sir Bubbles"}, ""},
+		{`say "it's"`, []string{"say", "it's"}, ""},
+		{`say 'a "quote"'`, []string{"say", `a "quote"`}, ""},
+		{`say "a \"quote\""`, []string{"say", `a "quote"`}, ""},
+		{`say 'no \escapes'`, []string{"say", `no \escapes`}, ""},
+		{`say back\\slash`, []string{"say", `back\slash`}, ""},
+		{`say half"quoted word"s`, []string{"say", "halfquoted words"}, ""},
+		{`nickname 12 ""`, []string{"nickname", "12", ""}, ""},
+		{`nickname 12 ''`, []string{"nickname", "12", ""}, ""},
+		{`nickname 12 "Sir Bubbles`, nil, `Missing closing " quote`},
+		{`nickname 12 'Sir Bubbles`, nil, `Missing closing ' quote`},
+		{`say "it's' done`, nil, `Missing closing " quote`},
+		{`say trailing\`, nil, `Nothing to escape after the final \`},
+	}
+
+	for _, test := range tests {
+		words, err := tokenize(test.line)
+		if test.wantErr != "" {
+			if err == nil || err.Error() != test.wantErr {
+				t.Errorf("tokenize(%q) error = %v, want %q", test.line, err, test.wantErr)
+			}
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("tokenize(%q) error = %v", test.line, err)
+		} else if !slices.Equal(words, test.want) {
+			t.Errorf("tokenize(%q) = %q, want %q", test.line, words, test.want)
+		}
+	}
+}
@@ -0,0 +1,147 @@
+package game
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// DefaultBall is thrown when no other ball is chosen
+const DefaultBall = "poke-ball"
+
+// The catch rate formula from the generation III and IV games. A catch value of at least maxCatchValue always catches,
+// anything lower has to pass shakeChecks random checks, the first three of which each shake the ball once.
+const (
+	maxCatchValue = 255
+	shakeChecks = 4
+	shakeRollRange = 65536
+)
+
+// Status is a status condition, which makes a wild pokemon easier to catch
+type Status string
+
+const (
+	StatusNone Status = "none"
+	StatusSleep Status = "sleep"
+	StatusFreeze Status = "freeze"
+	StatusParalysis Status = "paralysis"
+	StatusPoison Status = "poison"
+	StatusBurn Status = "burn"
+)
+
+var statusModifiers = map[Status]float64{
+	StatusNone: 1,
+	StatusSleep: 2,
+	StatusFreeze: 2,
+	StatusParalysis: 1.5,
+	StatusPoison: 1.5,
+	StatusBurn: 1.5,
+}
+
+// Modifiers of the balls whose effect doesn't depend on the battle. Any other ball counts as a Poke Ball.
+var ballModifiers = map[string]float64{
+	"poke-ball": 1,
+	"great-ball": 1.5,
+	"ultra-ball": 2,
+	"master-ball": 255,
+	"safari-ball": 1.5,
+	"sport-ball": 1.5,
+	"premier-ball": 1,
+	"luxury-ball": 1,
+	"heal-ball": 1,
+	"cherish-ball": 1,
+}
+
+// CatchAttempt is everything that decides whether a thrown ball catches a wild pokemon
+type CatchAttempt struct {
+	CaptureRate int  // The species' capture rate, from 3 for legendaries up to 255
+	RemainingHP float64  // The fraction of its HP the pokemon has left, above 0 and at most 1
+	Status Status
+	Ball string
+}
+
+type CatchOutcome struct {
+	Shakes int  // How many times the ball shook, from 0 to 3
+	IsCaught bool
+}
+
+/*-------------------------------------------------------------------------------------------------------------------------------------------------------------------*/
+
+func (a CatchAttempt) Validate() (err error) {
+	if a.CaptureRate < 1 || a.CaptureRate > 255 {
+		return fmt.Errorf("capture rate %d is outside 1 to 255", a.CaptureRate)
+	} else if a.RemainingHP <= 0 || a.RemainingHP > 1 {
+		return fmt.Errorf("remaining HP %.2f is outside 0 to 1", a.RemainingHP)
+	} else if _, ok := statusModifiers[a.Status]; !ok {
+		return fmt.Errorf("unknown status %q", a.Status)
+	}
+
+	return nil
+}
+
+// Odds is the chance, from 0 to 1, that the ball catches the pokemon
+func (a CatchAttempt) Odds() (odds float64) {
+	catchValue := a.catchValue()
+	if catchValue >= maxCatchValue {
+		return 1
+	}
+
+	return math.Pow(shakeThreshold(catchValue) / shakeRollRange, shakeChecks)
+}
+
+// Throw throws the ball once. intn must return a random number from 0 up to but not including n, like rand.Intn.
+func (a CatchAttempt) Throw(intn func(n int) int) (outcome CatchOutcome) {
+	catchValue := a.catchValue()
+	if catchValue >= maxCatchValue {
+		return CatchOutcome{Shakes: shakeChecks - 1, IsCaught: true}
+	}
+
+	threshold := shakeThreshold(catchValue)
+	for check := 1; check <= shakeChecks; check++ {
+		if float64(intn(shakeRollRange)) >= threshold {
+			return outcome
+		} else if check < shakeChecks {
+			outcome.Shakes++
+		}
+	}
+
+	outcome.IsCaught = true
+	return outcome
+}
+
+// catchValue is the a of the formula: 3 times the max HP minus twice the current HP, over 3 times the max HP, times the
+// capture rate, the ball and the status modifiers
+func (a CatchAttempt) catchValue() (value float64) {
+	hpFactor := (3 - 2 * a.RemainingHP) / 3
+	return hpFactor * float64(a.CaptureRate) * BallModifier(a.Ball) * statusModifiers[a.Status]
+}
+
+// shakeThreshold is the b of the formula, which each shake check's random number has to be under
+func shakeThreshold(catchValue float64) (threshold float64) {
+	return 1048560 / math.Sqrt(math.Sqrt(16711680 / catchValue))
+}
+
+func BallModifier(ball string) (modifier float64) {
+	if modifier, ok := ballModifiers[ball]; ok {
+		return modifier
+	}
+
+	return 1
+}
+
+func ParseStatus(name string) (status Status, err error) {
+	status = Status(strings.ToLower(name))
+	if _, ok := statusModifiers[status]; !ok {
+		return "", fmt.Errorf("unknown status %q, choose one of %s", name, strings.Join(StatusNames(), ", "))
+	}
+
+	return status, nil
+}
+
+func StatusNames() (names []string) {
+	for _, status := range []Status{StatusNone, StatusSleep, StatusFreeze, StatusParalysis, StatusPoison, StatusBurn} {
+		names = append(names, string(status))
+	}
+
+	return names
+}
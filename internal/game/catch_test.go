@@ -0,0 +1,182 @@
+package game
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+// rolls stands in for rand.Intn, handing out the given numbers in order and failing the test if more are asked for
+func rolls(t *testing.T, numbers ...int) (intn func(n int) int, used func() int) {
+	next := 0
+	intn = func(n int) int {
+		if n != shakeRollRange {
+			t.Fatalf("intn(%d), want intn(%d)", n, shakeRollRange)
+		} else if next == len(numbers) {
+			t.Fatalf("rolled %d times, more than the %d rolls given", next + 1, len(numbers))
+		}
+		next++
+		return numbers[next - 1]
+	}
+	return intn, func() int { return next }
+}
+
+func TestOdds(t *testing.T) {
+	tests := []struct {
+		name string
+		attempt CatchAttempt
+		want float64
+	}{
+		{"capture rate 255, Poke Ball, full HP", CatchAttempt{CaptureRate: 255, RemainingHP: 1, Status: StatusNone, Ball: "poke-ball"}, 0.3333},
+		{"capture rate 45, Poke Ball, full HP", CatchAttempt{CaptureRate: 45, RemainingHP: 1, Status: StatusNone, Ball: "poke-ball"}, 0.0588},
+		{"capture rate 3, Poke Ball, full HP", CatchAttempt{CaptureRate: 3, RemainingHP: 1, Status: StatusNone, Ball: "poke-ball"}, 0.0039},
+		{"capture rate 45, Ultra Ball, asleep at a tenth of its HP", CatchAttempt{CaptureRate: 45, RemainingHP: 0.1, Status: StatusSleep, Ball: "ultra-ball"}, 0.6588},
+		{"Master Ball", CatchAttempt{CaptureRate: 3, RemainingHP: 1, Status: StatusNone, Ball: "master-ball"}, 1},
+		{"catch value of 255 or more", CatchAttempt{CaptureRate: 255, RemainingHP: 0.01, Status: StatusSleep, Ball: "great-ball"}, 1},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := test.attempt.Odds(); math.Abs(got - test.want) > 0.0005 {
+				t.Errorf("Odds() = %.4f, want %.4f", got, test.want)
+			}
+		})
+	}
+}
+
+func TestOddsModifiers(t *testing.T) {
+	base := CatchAttempt{CaptureRate: 45, RemainingHP: 1, Status: StatusNone, Ball: "poke-ball"}
+	better := []struct {
+		name string
+		change func(a *CatchAttempt)
+	}{
+		{"great ball", func(a *CatchAttempt) { a.Ball = "great-ball" }},
+		{"ultra ball", func(a *CatchAttempt) { a.Ball = "ultra-ball" }},
+		{"half HP", func(a *CatchAttempt) { a.RemainingHP = 0.5 }},
+		{"paralysis", func(a *CatchAttempt) { a.Status = StatusParalysis }},
+		{"sleep", func(a *CatchAttempt) { a.Status = StatusSleep }},
+	}
+
+	for _, test := range better {
+		attempt := base
+		test.change(&attempt)
+		if attempt.Odds() <= base.Odds() {
+			t.Errorf("%s: Odds() = %.4f, want better than %.4f", test.name, attempt.Odds(), base.Odds())
+		}
+	}
+
+	// Each modifier multiplies the catch value
+	for _, test := range []struct {
+		attempt CatchAttempt
+		factor float64
+	}{
+		{CatchAttempt{CaptureRate: 45, RemainingHP: 1, Status: StatusNone, Ball: "great-ball"}, 1.5},
+		{CatchAttempt{CaptureRate: 45, RemainingHP: 1, Status: StatusNone, Ball: "ultra-ball"}, 2},
+		{CatchAttempt{CaptureRate: 45, RemainingHP: 1, Status: StatusNone, Ball: "dive-ball"}, 1},
+		{CatchAttempt{CaptureRate: 45, RemainingHP: 1, Status: StatusFreeze, Ball: "poke-ball"}, 2},
+		{CatchAttempt{CaptureRate: 45, RemainingHP: 1, Status: StatusBurn, Ball: "poke-ball"}, 1.5},
+		{CatchAttempt{CaptureRate: 45, RemainingHP: 0.01, Status: StatusNone, Ball: "poke-ball"}, 2.98},
+	} {
+		if got := test.attempt.catchValue() / base.catchValue(); math.Abs(got - test.factor) > 1e-9 {
+			t.Errorf("%+v has %.3f times the catch value, want %.3f", test.attempt, got, test.factor)
+		}
+	}
+}
+
+func TestThrow(t *testing.T) {
+	attempt := CatchAttempt{CaptureRate: 255, RemainingHP: 1, Status: StatusNone, Ball: "poke-ball"}
+	threshold := int(shakeThreshold(attempt.catchValue()))
+	pass, fail := threshold - 1, threshold + 1
+
+	tests := []struct {
+		name string
+		rolls []int
+		want CatchOutcome
+	}{
+		{"breaks out at once", []int{fail}, CatchOutcome{Shakes: 0, IsCaught: false}},
+		{"one shake", []int{pass, fail}, CatchOutcome{Shakes: 1, IsCaught: false}},
+		{"two shakes", []int{pass, pass, fail}, CatchOutcome{Shakes: 2, IsCaught: false}},
+		{"three shakes then out", []int{pass, pass, pass, fail}, CatchOutcome{Shakes: 3, IsCaught: false}},
+		{"caught", []int{pass, pass, pass, pass}, CatchOutcome{Shakes: 3, IsCaught: true}},
+		{"lowest rolls", []int{0, 0, 0, 0}, CatchOutcome{Shakes: 3, IsCaught: true}},
+		{"highest roll", []int{shakeRollRange - 1}, CatchOutcome{Shakes: 0, IsCaught: false}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			intn, used := rolls(t, test.rolls...)
+			if got := attempt.Throw(intn); got != test.want {
+				t.Errorf("Throw() = %+v, want %+v", got, test.want)
+			}
+			if used() != len(test.rolls) {
+				t.Errorf("rolled %d times, want %d", used(), len(test.rolls))
+			}
+		})
+	}
+}
+
+func TestThrowGuaranteedCatch(t *testing.T) {
+	for _, attempt := range []CatchAttempt{
+		{CaptureRate: 3, RemainingHP: 1, Status: StatusNone, Ball: "master-ball"},
+		{CaptureRate: 255, RemainingHP: 0.01, Status: StatusSleep, Ball: "great-ball"},
+	} {
+		intn, used := rolls(t)
+		if got := attempt.Throw(intn); got != (CatchOutcome{Shakes: 3, IsCaught: true}) {
+			t.Errorf("%+v: Throw() = %+v, want caught after 3 shakes", attempt, got)
+		}
+		if used() != 0 {
+			t.Errorf("%+v: rolled %d times for a guaranteed catch", attempt, used())
+		}
+	}
+}
+
+// TestThrowMatchesOdds throws many times with a fixed seed, so it always gets the same result
+func TestThrowMatchesOdds(t *testing.T) {
+	random := rand.New(rand.NewSource(1))
+	for _, attempt := range []CatchAttempt{
+		{CaptureRate: 255, RemainingHP: 1, Status: StatusNone, Ball: "poke-ball"},
+		{CaptureRate: 45, RemainingHP: 0.5, Status: StatusParalysis, Ball: "great-ball"},
+	} {
+		const throws = 20000
+		caught := 0
+		for i := 0; i < throws; i++ {
+			if attempt.Throw(random.Intn).IsCaught {
+				caught++
+			}
+		}
+
+		if got := float64(caught) / throws; math.Abs(got - attempt.Odds()) > 0.015 {
+			t.Errorf("%+v caught %.3f of the time, want about %.3f", attempt, got, attempt.Odds())
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	valid := CatchAttempt{CaptureRate: 45, RemainingHP: 1, Status: StatusNone, Ball: "poke-ball"}
+	if err := valid.Validate(); err != nil {
+		t.Errorf("Validate() = %v for a valid attempt", err)
+	}
+
+	for _, attempt := range []CatchAttempt{
+		{CaptureRate: 0, RemainingHP: 1, Status: StatusNone},
+		{CaptureRate: 256, RemainingHP: 1, Status: StatusNone},
+		{CaptureRate: 45, RemainingHP: 0, Status: StatusNone},
+		{CaptureRate: 45, RemainingHP: 1.01, Status: StatusNone},
+		{CaptureRate: 45, RemainingHP: 1, Status: "confused"},
+	} {
+		if err := attempt.Validate(); err == nil {
+			t.Errorf("Validate() accepted %+v", attempt)
+		}
+	}
+}
+
+func TestParseStatus(t *testing.T) {
+	for _, name := range []string{"none", "sleep", "Sleep", "FREEZE", "paralysis", "poison", "burn"} {
+		if _, err := ParseStatus(name); err != nil {
+			t.Errorf("ParseStatus(%q) = %v", name, err)
+		}
+	}
+	if _, err := ParseStatus("confused"); err == nil {
+		t.Error("ParseStatus(confused) succeeded")
+	}
+}
@@ -0,0 +1,50 @@
+package game
+
+// EncounterSlot is a pokemon that can appear with one encounter method, and how likely it is to be the one that does
+type EncounterSlot struct {
+	Pokemon string
+	Chance int  // Relative weight against the other slots of the method, usually a percent
+	MinLevel int
+	MaxLevel int
+}
+
+// WildPokemon is a pokemon that has appeared and can be caught
+type WildPokemon struct {
+	Pokemon string
+	Level int
+}
+
+/*-------------------------------------------------------------------------------------------------------------------------------------------------------------------*/
+
+// RollEncounter picks one of the slots weighted by their chances, then a level in its range. It finds nothing when
+// there are no slots or none of them has a chance. intn is like rand.Intn.
+func RollEncounter(slots []EncounterSlot, intn func(n int) int) (wild WildPokemon, isFound bool) {
+	total := 0
+	for _, slot := range slots {
+		total += max(slot.Chance, 0)
+	}
+	if total == 0 {
+		return wild, false
+	}
+
+	roll := intn(total)
+	for _, slot := range slots {
+		if roll >= max(slot.Chance, 0) {
+			roll -= max(slot.Chance, 0)
+			continue
+		}
+
+		level := slot.MinLevel
+		if slot.MaxLevel > slot.MinLevel {
+			level += intn(slot.MaxLevel - slot.MinLevel + 1)
+		}
+		return WildPokemon{Pokemon: slot.Pokemon, Level: level}, true
+	}
+
+	return wild, false
+}
+
+// RollBite is whether anything takes the bait, for a method that only meets a pokemon rate percent of the time
+func RollBite(rate int, intn func(n int) int) (isBite bool) {
+	return intn(100) < rate
+}
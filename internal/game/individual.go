@@ -0,0 +1,136 @@
+package game
+
+import (
+	"fmt"
+)
+
+// Individual values range from 0 to MaxIV, and levels from 1 to MaxLevel. One wild pokemon in shinyOdds is shiny.
+const (
+	MaxIV = 31
+	MaxLevel = 100
+	shinyOdds = 8192
+)
+
+// Stats are the six stats of a pokemon: its species' base stats, its individual values, or the actual stats they add up to
+type Stats struct {
+	HP int `json:"hp"`
+	Attack int `json:"attack"`
+	Defense int `json:"defense"`
+	SpecialAttack int `json:"special_attack"`
+	SpecialDefense int `json:"special_defense"`
+	Speed int `json:"speed"`
+}
+
+// Nature raises one stat other than HP by a tenth and lowers another by a tenth, or does nothing when they're the same
+type Nature string
+
+// Gender is the gender of one pokemon, decided by its species' gender rate
+type Gender string
+
+const (
+	GenderMale Gender = "male"
+	GenderFemale Gender = "female"
+	GenderNone Gender = "genderless"
+)
+
+// The natures in the games' order: the nth raises stat n/5 and lowers stat n%5 of attack, defense, speed, special
+// attack and special defense
+var natures = []Nature{
+	"hardy", "lonely", "brave", "adamant", "naughty",
+	"bold", "docile", "relaxed", "impish", "lax",
+	"timid", "hasty", "serious", "jolly", "naive",
+	"modest", "mild", "quiet", "bashful", "rash",
+	"calm", "gentle", "sassy", "careful", "quirky",
+}
+
+// Individual is what makes one wild pokemon different from the others of its species
+type Individual struct {
+	Level int
+	IVs Stats
+	Nature Nature
+	Gender Gender
+	IsShiny bool
+}
+
+/*-------------------------------------------------------------------------------------------------------------------------------------------------------------------*/
+
+// RollIndividual rolls the IVs, nature, gender and shininess of a wild pokemon. genderRate is the species' chance of
+// being female in eighths, or -1 for a genderless species. intn is like rand.Intn.
+func RollIndividual(level int, genderRate int, intn func(n int) int) (individual Individual) {
+	individual.Level = level
+	individual.IVs = Stats{
+		HP: intn(MaxIV + 1),
+		Attack: intn(MaxIV + 1),
+		Defense: intn(MaxIV + 1),
+		SpecialAttack: intn(MaxIV + 1),
+		SpecialDefense: intn(MaxIV + 1),
+		Speed: intn(MaxIV + 1),
+	}
+	individual.Nature = natures[intn(len(natures))]
+
+	if genderRate < 0 {
+		individual.Gender = GenderNone
+	} else if intn(8) < genderRate {
+		individual.Gender = GenderFemale
+	} else {
+		individual.Gender = GenderMale
+	}
+
+	individual.IsShiny = intn(shinyOdds) == 0
+	return individual
+}
+
+// ActualStats works out a pokemon's stats from its species' base stats, its IVs, level and nature, with the formula of
+// the generation III games onwards for a pokemon without effort values
+func ActualStats(base Stats, ivs Stats, level int, nature Nature) (stats Stats) {
+	stats.HP = (2 * base.HP + ivs.HP) * level / 100 + level + 10
+
+	baseStats := base.others()
+	ivStats := ivs.others()
+	raised, lowered := nature.effect()
+	for i, stat := range stats.others() {
+		*stat = (2 * *baseStats[i] + *ivStats[i]) * level / 100 + 5
+		if i == raised && i != lowered {
+			*stat = *stat * 11 / 10
+		} else if i == lowered && i != raised {
+			*stat = *stat * 9 / 10
+		}
+	}
+
+	return stats
+}
+
+// ValidateIVs checks the stats are IVs, all within 0 to MaxIV
+func (s Stats) ValidateIVs() (err error) {
+	for _, iv := range append([]*int{&s.HP}, s.others()...) {
+		if *iv < 0 || *iv > MaxIV {
+			return fmt.Errorf("IV %d is outside 0 to %d", *iv, MaxIV)
+		}
+	}
+
+	return nil
+}
+
+// others are the stats a nature can change, in the order of the natures table
+func (s *Stats) others() (stats []*int) {
+	return []*int{&s.Attack, &s.Defense, &s.Speed, &s.SpecialAttack, &s.SpecialDefense}
+}
+
+// effect is which of the stats in others the nature raises and lowers, both -1 for a nature nobody has heard of
+func (n Nature) effect() (raised int, lowered int) {
+	for i, nature := range natures {
+		if nature == n {
+			return i / 5, i % 5
+		}
+	}
+
+	return -1, -1
+}
+
+func ParseNature(name string) (nature Nature, err error) {
+	if raised, _ := Nature(name).effect(); raised == -1 {
+		return "", fmt.Errorf("unknown nature %q", name)
+	}
+
+	return Nature(name), nil
+}
@@ -0,0 +1,530 @@
+package lineedit
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode"
+)
+/*-------------------------------------------------------------------------------------------------------------------------------------------------------------------*/
+
+const DefaultMaxHistory = 1000
+
+// ErrInterrupted is returned by ReadLine when Ctrl-C is pressed at the prompt
+var ErrInterrupted = errors.New("interrupted")
+
+const (
+	keyCtrlA = 1
+	keyCtrlB = 2
+	keyCtrlC = 3
+	keyCtrlD = 4
+	keyCtrlE = 5
+	keyCtrlF = 6
+	keyCtrlG = 7
+	keyBackspace = 8
+	keyTab = 9
+	keyLineFeed = 10
+	keyCtrlK = 11
+	keyCtrlL = 12
+	keyEnter = 13
+	keyCtrlN = 14
+	keyCtrlP = 16
+	keyCtrlR = 18
+	keyCtrlU = 21
+	keyCtrlW = 23
+	keyEscape = 27
+	keyDelete = 127
+)
+
+// Keys that arrive as escape sequences are given values outside the range of runes
+const (
+	keyUp = -(iota + 1)
+	keyDown
+	keyRight
+	keyLeft
+	keyHome
+	keyEnd
+	keyDeleteForward
+	keyUnknown
+)
+
+/*-------------------------------------------------------------------------------------------------------------------------------------------------------------------*/
+
+// Editor reads lines with editing, history and tab completion when its input is a terminal, and plain lines otherwise
+type Editor struct{
+	in *os.File
+	out io.Writer
+	reader *bufio.Reader
+	isTerminal bool
+
+	history []string
+	historyPath string
+	maxHistory int
+
+	// Complete returns the candidates for the word being typed, given the complete words before it on the line
+	Complete func(words []string, partial string) (candidates []string)
+
+	// Per-line editing state
+	prompt string
+	buffer []rune
+	cursor int
+	historyIndex int
+	draft []rune  // What was typed before browsing history
+	lastKeyWasTab bool
+}
+
+/*-------------------------------------------------------------------------------------------------------------------------------------------------------------------*/
+
+func (e *Editor) IsTerminal() (isTerminal bool) {
+	return e.isTerminal
+}
+
+// LoadHistory reads earlier lines from path, one per line, and appends every new line to it from now on.
+// A missing file is fine; it is created with the first line.
+func (e *Editor) LoadHistory(path string) (err error) {
+	e.historyPath = path
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	for _, line := range strings.Split(string(data), "\n") {
+		if line != "" {
+			e.history = append(e.history, line)
+		}
+	}
+	if len(e.history) > e.maxHistory {
+		e.history = e.history[len(e.history) - e.maxHistory:]
+	}
+
+	return nil
+}
+
+// AddHistory remembers a line for the up arrow and Ctrl-R, skipping blanks and immediate repeats
+func (e *Editor) AddHistory(line string) (err error) {
+	line = strings.TrimSpace(line)
+	if line == "" || strings.Contains(line, "\n") || (len(e.history) > 0 && e.history[len(e.history) - 1] == line) {
+		return nil
+	}
+
+	e.history = append(e.history, line)
+	if len(e.history) > e.maxHistory {
+		e.history = e.history[len(e.history) - e.maxHistory:]
+		return e.rewriteHistoryFile()
+	}
+
+	return e.appendHistoryFile(line)
+}
+
+// ReadLine shows prompt and returns the line typed, without the newline. It returns io.EOF at the end of input
+// or on Ctrl-D at an empty prompt, and ErrInterrupted on Ctrl-C.
+func (e *Editor) ReadLine(prompt string) (line string, err error) {
+	if !e.isTerminal {
+		return e.readPlainLine(prompt)
+	}
+
+	previous, err := makeRaw(e.in.Fd())
+	if err != nil {
+		return e.readPlainLine(prompt)
+	}
+	defer restore(e.in.Fd(), previous)
+
+	e.prompt = prompt
+	e.buffer = nil
+	e.cursor = 0
+	e.historyIndex = len(e.history)
+	e.draft = nil
+	e.lastKeyWasTab = false
+	e.refresh()
+
+	for {
+		key, err := e.readKey()
+		if err != nil {
+			e.write("\r\n")
+			return "", err
+		}
+
+		isTab := key == keyTab
+		switch key {
+		case keyEnter, keyLineFeed:
+			e.write("\r\n")
+			return string(e.buffer), nil
+		case keyCtrlC:
+			e.write("^C\r\n")
+			return "", ErrInterrupted
+		case keyCtrlD:
+			if len(e.buffer) == 0 {
+				e.write("\r\n")
+				return "", io.EOF
+			}
+			e.deleteForward()
+		case keyBackspace, keyDelete:
+			e.deleteBackward()
+		case keyDeleteForward:
+			e.deleteForward()
+		case keyLeft, keyCtrlB:
+			e.moveTo(e.cursor - 1)
+		case keyRight, keyCtrlF:
+			e.moveTo(e.cursor + 1)
+		case keyHome, keyCtrlA:
+			e.moveTo(0)
+		case keyEnd, keyCtrlE:
+			e.moveTo(len(e.buffer))
+		case keyUp, keyCtrlP:
+			e.browseHistory(-1)
+		case keyDown, keyCtrlN:
+			e.browseHistory(1)
+		case keyCtrlK:
+			e.buffer = e.buffer[:e.cursor]
+			e.refresh()
+		case keyCtrlU:
+			e.buffer = e.buffer[e.cursor:]
+			e.cursor = 0
+			e.refresh()
+		case keyCtrlW:
+			e.deleteWordBackward()
+		case keyCtrlL:
+			e.write("\x1b[H\x1b[2J")
+			e.refresh()
+		case keyTab:
+			e.complete()
+		case keyCtrlR:
+			if line, ok, err := e.reverseSearch(); err != nil {
+				e.write("\r\n")
+				return "", err
+			} else if ok {
+				e.write("\r\n")
+				return line, nil
+			}
+		default:
+			if key >= ' ' {
+				e.insert(rune(key))
+			}
+		}
+		e.lastKeyWasTab = isTab
+	}
+}
+
+func (e *Editor) readPlainLine(prompt string) (line string, err error) {
+	e.write(prompt)
+
+	line, err = e.reader.ReadString('\n')
+	if err != nil && (err != io.EOF || line == "") {
+		return "", err
+	}
+
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+/*-------------------------------------------------------------------------------------------------------------------------------------------------------------------*/
+// Editing
+
+func (e *Editor) insert(char rune) {
+	e.buffer = append(e.buffer[:e.cursor], append([]rune{char}, e.buffer[e.cursor:]...)...)
+	e.cursor++
+	e.refresh()
+	return
+}
+
+func (e *Editor) insertString(text string) {
+	runes := []rune(text)
+	e.buffer = append(e.buffer[:e.cursor], append(runes, e.buffer[e.cursor:]...)...)
+	e.cursor += len(runes)
+	e.refresh()
+	return
+}
+
+func (e *Editor) deleteBackward() {
+	if e.cursor == 0 {
+		return
+	}
+
+	e.buffer = append(e.buffer[:e.cursor - 1], e.buffer[e.cursor:]...)
+	e.cursor--
+	e.refresh()
+	return
+}
+
+func (e *Editor) deleteForward() {
+	if e.cursor == len(e.buffer) {
+		return
+	}
+
+	e.buffer = append(e.buffer[:e.cursor], e.buffer[e.cursor + 1:]...)
+	e.refresh()
+	return
+}
+
+func (e *Editor) deleteWordBackward() {
+	start := e.cursor
+	for start > 0 && unicode.IsSpace(e.buffer[start - 1]) {
+		start--
+	}
+	for start > 0 && !unicode.IsSpace(e.buffer[start - 1]) {
+		start--
+	}
+
+	e.buffer = append(e.buffer[:start], e.buffer[e.cursor:]...)
+	e.cursor = start
+	e.refresh()
+	return
+}
+
+func (e *Editor) moveTo(position int) {
+	e.cursor = min(max(position, 0), len(e.buffer))
+	e.refresh()
+	return
+}
+
+func (e *Editor) browseHistory(step int) {
+	index := e.historyIndex + step
+	if index < 0 || index > len(e.history) {
+		return
+	}
+
+	if e.historyIndex == len(e.history) {
+		e.draft = append([]rune(nil), e.buffer...)
+	}
+
+	e.historyIndex = index
+	if index == len(e.history) {
+		e.buffer = append([]rune(nil), e.draft...)
+	} else {
+		e.buffer = []rune(e.history[index])
+	}
+	e.cursor = len(e.buffer)
+	e.refresh()
+	return
+}
+
+/*-------------------------------------------------------------------------------------------------------------------------------------------------------------------*/
+// Tab completion
+
+// complete fills in the word before the cursor. With one candidate the word is finished off, with several it is
+// extended as far as they agree, and pressing tab a second time lists them.
+func (e *Editor) complete() {
+	if e.Complete == nil {
+		return
+	}
+
+	beforeCursor := string(e.buffer[:e.cursor])
+	words := strings.Fields(beforeCursor)
+	partial := ""
+	if len(words) > 0 && !strings.HasSuffix(beforeCursor, " ") {
+		partial = words[len(words) - 1]
+		words = words[:len(words) - 1]
+	}
+
+	var candidates []string
+	for _, candidate := range e.Complete(words, partial) {
+		if strings.HasPrefix(candidate, partial) {
+			candidates = append(candidates, candidate)
+		}
+	}
+
+	if len(candidates) == 0 {
+		return
+	} else if len(candidates) == 1 {
+		e.insertString(strings.TrimPrefix(candidates[0], partial) + " ")
+		return
+	}
+
+	prefix := commonPrefix(candidates)
+	if len(prefix) > len(partial) {
+		e.insertString(strings.TrimPrefix(prefix, partial))
+		return
+	}
+
+	if e.lastKeyWasTab {
+		e.write("\r\n" + strings.Join(candidates, "  ") + "\r\n")
+		e.refresh()
+	}
+	return
+}
+
+func commonPrefix(words []string) (prefix string) {
+	prefix = words[0]
+	for _, word := range words[1:] {
+		for !strings.HasPrefix(word, prefix) {
+			prefix = prefix[:len(prefix) - 1]
+		}
+	}
+	return prefix
+}
+
+/*-------------------------------------------------------------------------------------------------------------------------------------------------------------------*/
+// Ctrl-R search
+
+// reverseSearch looks back through history for lines containing what is typed. Ctrl-R again finds an older match,
+// Enter runs the match, Ctrl-G or Escape gives up, and any other key leaves the match on the line for editing.
+func (e *Editor) reverseSearch() (line string, isAccepted bool, err error) {
+	var query []rune
+	matchIndex := len(e.history)
+	match := ""
+
+	search := func(from int) {
+		for i := from; i >= 0; i-- {
+			if strings.Contains(e.history[i], string(query)) {
+				matchIndex = i
+				match = e.history[i]
+				return
+			}
+		}
+	}
+
+	for {
+		e.write(fmt.Sprintf("\r\x1b[K(reverse-i-search)`%s': %s", string(query), match))
+
+		key, err := e.readKey()
+		if err != nil {
+			return "", false, err
+		}
+
+		switch {
+		case key == keyCtrlR:
+			search(matchIndex - 1)
+		case key == keyBackspace || key == keyDelete:
+			if len(query) > 0 {
+				query = query[:len(query) - 1]
+				matchIndex, match = len(e.history), ""
+				search(len(e.history) - 1)
+			}
+		case key == keyEnter || key == keyLineFeed:
+			e.refreshWith([]rune(match), len([]rune(match)))
+			return match, true, nil
+		case key == keyCtrlG || key == keyEscape || key == keyCtrlC:
+			e.refresh()
+			return "", false, nil
+		case key >= ' ':
+			query = append(query, rune(key))
+			search(min(matchIndex, len(e.history) - 1))
+		default:
+			if match != "" {
+				e.buffer = []rune(match)
+				e.cursor = len(e.buffer)
+			}
+			e.refresh()
+			return "", false, nil
+		}
+	}
+}
+
+/*-------------------------------------------------------------------------------------------------------------------------------------------------------------------*/
+// Terminal input and output
+
+// readKey reads one keypress, turning the escape sequences for arrows, Home, End and Delete into key constants
+func (e *Editor) readKey() (key int, err error) {
+	char, _, err := e.reader.ReadRune()
+	if err != nil {
+		return 0, err
+	} else if char != keyEscape {
+		return int(char), nil
+	}
+
+	// A lone Escape has nothing buffered behind it
+	if e.reader.Buffered() == 0 {
+		return keyEscape, nil
+	}
+
+	introducer, _, err := e.reader.ReadRune()
+	if err != nil {
+		return 0, err
+	} else if introducer != '[' && introducer != 'O' {
+		return keyUnknown, nil
+	}
+
+	sequence := ""
+	for {
+		char, _, err = e.reader.ReadRune()
+		if err != nil {
+			return 0, err
+		}
+		sequence += string(char)
+		if char >= '@' && char <= '~' && !(char >= '0' && char <= '9') && char != ';' {
+			break
+		}
+	}
+
+	switch sequence {
+	case "A": return keyUp, nil
+	case "B": return keyDown, nil
+	case "C": return keyRight, nil
+	case "D": return keyLeft, nil
+	case "H", "1~", "7~": return keyHome, nil
+	case "F", "4~", "8~": return keyEnd, nil
+	case "3~": return keyDeleteForward, nil
+	}
+
+	return keyUnknown, nil
+}
+
+func (e *Editor) refresh() {
+	e.refreshWith(e.buffer, e.cursor)
+	return
+}
+
+// refreshWith redraws the prompt and text, then walks the cursor back from the end of the line
+func (e *Editor) refreshWith(buffer []rune, cursor int) {
+	output := "\r\x1b[K" + e.prompt + string(buffer)
+	if back := len(buffer) - cursor; back > 0 {
+		output += fmt.Sprintf("\x1b[%dD", back)
+	}
+
+	e.write(output)
+	return
+}
+
+func (e *Editor) write(text string) {
+	io.WriteString(e.out, text)
+	return
+}
+
+/*-------------------------------------------------------------------------------------------------------------------------------------------------------------------*/
+// History file
+
+func (e *Editor) appendHistoryFile(line string) (err error) {
+	if e.historyPath == "" {
+		return nil
+	}
+
+	if err = os.MkdirAll(filepath.Dir(e.historyPath), 0o755); err != nil {
+		return err
+	}
+
+	file, err := os.OpenFile(e.historyPath, os.O_APPEND | os.O_CREATE | os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	_, err = file.WriteString(line + "\n")
+	return err
+}
+
+func (e *Editor) rewriteHistoryFile() (err error) {
+	if e.historyPath == "" {
+		return nil
+	}
+
+	return os.WriteFile(e.historyPath, []byte(strings.Join(e.history, "\n") + "\n"), 0o600)
+}
+
+/*==================================================================================================================================*/
+
+func New(in *os.File, out io.Writer) (editor *Editor) {
+	editor = &Editor{
+		in: in,
+		out: out,
+		reader: bufio.NewReader(in),
+		isTerminal: isTerminal(in.Fd()),
+		maxHistory: DefaultMaxHistory,
+	}
+	return editor
+}
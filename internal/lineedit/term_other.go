@@ -0,0 +1,21 @@
+//go:build !linux && !darwin && !dragonfly && !freebsd && !netbsd && !openbsd
+
+package lineedit
+
+import "errors"
+
+// Raw mode isn't supported here, so the editor always falls back to reading plain lines
+
+type terminalState struct{}
+
+func isTerminal(fd uintptr) (isTerminal bool) {
+	return false
+}
+
+func makeRaw(fd uintptr) (previous *terminalState, err error) {
+	return nil, errors.New("raw terminal mode is not supported on this platform")
+}
+
+func restore(fd uintptr, state *terminalState) (err error) {
+	return nil
+}
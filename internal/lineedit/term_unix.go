@@ -0,0 +1,59 @@
+//go:build linux || darwin || dragonfly || freebsd || netbsd || openbsd
+
+package lineedit
+
+import (
+	"syscall"
+	"unsafe"
+)
+
+type terminalState struct {
+	termios syscall.Termios
+}
+
+/*-------------------------------------------------------------------------------------------------------------------------------------------------------------------*/
+
+func isTerminal(fd uintptr) (isTerminal bool) {
+	_, err := getState(fd)
+	return err == nil
+}
+
+// makeRaw turns off echo, line buffering and signal keys so every keypress reaches the editor, and returns the state to restore
+func makeRaw(fd uintptr) (previous *terminalState, err error) {
+	previous, err = getState(fd)
+	if err != nil {
+		return nil, err
+	}
+
+	raw := previous.termios
+	raw.Iflag &^= syscall.IGNBRK | syscall.BRKINT | syscall.PARMRK | syscall.ISTRIP | syscall.INLCR | syscall.IGNCR | syscall.ICRNL | syscall.IXON
+	raw.Lflag &^= syscall.ECHO | syscall.ECHONL | syscall.ICANON | syscall.ISIG | syscall.IEXTEN
+	raw.Cflag &^= syscall.CSIZE | syscall.PARENB
+	raw.Cflag |= syscall.CS8
+	raw.Cc[syscall.VMIN] = 1
+	raw.Cc[syscall.VTIME] = 0
+
+	if err = setState(fd, &terminalState{termios: raw}); err != nil {
+		return nil, err
+	}
+	return previous, nil
+}
+
+func restore(fd uintptr, state *terminalState) (err error) {
+	return setState(fd, state)
+}
+
+func getState(fd uintptr) (state *terminalState, err error) {
+	state = &terminalState{}
+	if _, _, errno := syscall.Syscall(syscall.SYS_IOCTL, fd, uintptr(getTermios), uintptr(unsafe.Pointer(&state.termios))); errno != 0 {
+		return nil, errno
+	}
+	return state, nil
+}
+
+func setState(fd uintptr, state *terminalState) (err error) {
+	if _, _, errno := syscall.Syscall(syscall.SYS_IOCTL, fd, uintptr(setTermios), uintptr(unsafe.Pointer(&state.termios))); errno != 0 {
+		return errno
+	}
+	return nil
+}
@@ -0,0 +1,280 @@
+package player
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+)
+/*-------------------------------------------------------------------------------------------------------------------------------------------------------------------*/
+
+// A trainer carries a party of up to PartySize pokemon, and keeps the rest in BoxCount PC boxes of BoxCapacity each,
+// numbered from 1. Once the party has a pokemon it can never be emptied again. A trainer from a save made before there
+// were boxes keeps as many extra boxes past BoxCount as their pokemon need, which can be emptied but take no new pokemon.
+const (
+	PartySize = 6
+	BoxCount = 18
+	BoxCapacity = 30
+)
+
+var ErrStorageFull = errors.New("the party and every box are full")
+
+/*-------------------------------------------------------------------------------------------------------------------------------------------------------------------*/
+
+// HasRoom is whether another pokemon can be caught, in the party or any box
+func (t *Trainer) HasRoom() (hasRoom bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.freeBox() != -1
+}
+
+func (t *Trainer) GetParty() (party []Pokemon) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.pokemonWithIDs(t.party)
+}
+
+// GetBox is the pokemon in the numbered box, in the order they were put there
+func (t *Trainer) GetBox(box int) (pokemon []Pokemon, err error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if err = t.checkBox(box); err != nil {
+		return nil, err
+	}
+	return t.pokemonWithIDs(t.boxes[box - 1]), nil
+}
+
+// Locate is which box a pokemon is in, or 0 for the party
+func (t *Trainer) Locate(id int) (box int, isFound bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	box, _ = t.locate(id)
+	return box, box != -1
+}
+
+// Deposit moves a pokemon from the party into a box, or into the first box with room when box is 0
+func (t *Trainer) Deposit(id int, box int) (depositedIn int, err error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	from, i := t.locate(id)
+	if from == -1 {
+		return 0, fmt.Errorf("you don't have a pokemon with ID %d", id)
+	} else if from != 0 {
+		return 0, fmt.Errorf("it's already in box %d", from)
+	} else if len(t.party) == 1 {
+		return 0, errors.New("your party can't be left empty")
+	}
+
+	if box == 0 {
+		box = slices.IndexFunc(t.boxes[:BoxCount], func(ids []int) bool {
+			return len(ids) < BoxCapacity
+		}) + 1
+		if box == 0 {
+			return 0, errors.New("every box is full")
+		}
+	} else if err = t.checkBox(box); err != nil {
+		return 0, err
+	} else if box > BoxCount {
+		return 0, fmt.Errorf("box %d only keeps the pokemon from your old save, it can't take any more", box)
+	} else if len(t.boxes[box - 1]) == BoxCapacity {
+		return 0, fmt.Errorf("box %d is full", box)
+	}
+
+	t.party = slices.Delete(t.party, i, i + 1)
+	t.boxes[box - 1] = append(t.boxes[box - 1], id)
+	return box, nil
+}
+
+// Withdraw moves a pokemon from its box to the end of the party
+func (t *Trainer) Withdraw(id int) (withdrawnFrom int, err error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	from, i := t.locate(id)
+	if from == -1 {
+		return 0, fmt.Errorf("you don't have a pokemon with ID %d", id)
+	} else if from == 0 {
+		return 0, errors.New("it's already in your party")
+	} else if len(t.party) == PartySize {
+		return 0, fmt.Errorf("your party already has %d pokemon", PartySize)
+	}
+
+	t.boxes[from - 1] = slices.Delete(t.boxes[from - 1], i, i + 1)
+	t.party = append(t.party, id)
+	return from, nil
+}
+
+// Swap puts two pokemon in each other's places, whether that reorders the party or a box, or trades a party member for
+// a boxed pokemon
+func (t *Trainer) Swap(firstID int, secondID int) (err error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	firstBox, firstIndex := t.locate(firstID)
+	secondBox, secondIndex := t.locate(secondID)
+	if firstBox == -1 {
+		return fmt.Errorf("you don't have a pokemon with ID %d", firstID)
+	} else if secondBox == -1 {
+		return fmt.Errorf("you don't have a pokemon with ID %d", secondID)
+	} else if firstID == secondID {
+		return errors.New("a pokemon can't swap with itself")
+	}
+
+	first := t.place(firstBox)
+	second := t.place(secondBox)
+	first[firstIndex], second[secondIndex] = secondID, firstID
+	return nil
+}
+
+// Release lets a pokemon go for good. Its species stays in the pokedex.
+func (t *Trainer) Release(id int) (released Pokemon, err error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	box, i := t.locate(id)
+	if box == -1 {
+		return released, fmt.Errorf("you don't have a pokemon with ID %d", id)
+	} else if box == 0 && len(t.party) == 1 {
+		return released, errors.New("your party can't be left empty")
+	}
+
+	if box == 0 {
+		t.party = slices.Delete(t.party, i, i + 1)
+	} else {
+		t.boxes[box - 1] = slices.Delete(t.boxes[box - 1], i, i + 1)
+	}
+
+	j := t.pokemonIndexOf(id)
+	released = t.pokemon[j]
+	t.pokemon = slices.Delete(t.pokemon, j, j + 1)
+	return released, nil
+}
+
+// store puts a newly caught pokemon in the party, or the first box with room when the party is full
+func (t *Trainer) store(id int) (err error) {
+	box := t.freeBox()
+	if box == -1 {
+		return ErrStorageFull
+	} else if box == 0 {
+		t.party = append(t.party, id)
+	} else {
+		t.boxes[box - 1] = append(t.boxes[box - 1], id)
+	}
+
+	return nil
+}
+
+// freeBox is 0 when the party has room, else the first of the BoxCount boxes with room, or -1 when everything is full
+func (t *Trainer) freeBox() (box int) {
+	if len(t.party) < PartySize {
+		return 0
+	}
+
+	for i, ids := range t.boxes[:BoxCount] {
+		if len(ids) < BoxCapacity {
+			return i + 1
+		}
+	}
+	return -1
+}
+
+// locate is the box a pokemon is in, 0 for the party or -1 if it's nowhere, and its index there
+func (t *Trainer) locate(id int) (box int, index int) {
+	if i := slices.Index(t.party, id); i != -1 {
+		return 0, i
+	}
+
+	for b, ids := range t.boxes {
+		if i := slices.Index(ids, id); i != -1 {
+			return b + 1, i
+		}
+	}
+	return -1, -1
+}
+
+// place is the party for box 0, otherwise the numbered box
+func (t *Trainer) place(box int) (ids []int) {
+	if box == 0 {
+		return t.party
+	}
+	return t.boxes[box - 1]
+}
+
+func (t *Trainer) pokemonWithIDs(ids []int) (pokemon []Pokemon) {
+	pokemon = []Pokemon{}
+	for _, id := range ids {
+		if i := t.pokemonIndexOf(id); i != -1 {
+			pokemon = append(pokemon, t.pokemon[i])
+		}
+	}
+	return pokemon
+}
+
+// setStorage checks and sets where every pokemon is kept: each of them in exactly one place, no place over capacity,
+// no more than boxCount boxes, and the party not empty while there are any pokemon at all
+func (t *Trainer) setStorage(party []int, boxes [][]int, boxCount int) (err error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if len(party) > PartySize {
+		return fmt.Errorf("the party has %d pokemon, more than %d", len(party), PartySize)
+	} else if len(boxes) > boxCount {
+		return fmt.Errorf("there are %d boxes, more than %d", len(boxes), boxCount)
+	} else if len(party) == 0 && len(t.pokemon) > 0 {
+		return errors.New("the party is empty")
+	}
+
+	placed := map[int]bool{}
+	for b, ids := range append([][]int{party}, boxes...) {
+		if b > 0 && len(ids) > BoxCapacity {
+			return fmt.Errorf("box %d has %d pokemon, more than %d", b, len(ids), BoxCapacity)
+		}
+		for _, id := range ids {
+			if t.pokemonIndexOf(id) == -1 {
+				return fmt.Errorf("there's no pokemon %d to keep", id)
+			} else if placed[id] {
+				return fmt.Errorf("pokemon %d is kept in two places", id)
+			}
+			placed[id] = true
+		}
+	}
+	if len(placed) != len(t.pokemon) {
+		return errors.New("some pokemon aren't in the party or any box")
+	}
+
+	t.party = slices.Clone(party)
+	t.boxes = make([][]int, max(BoxCount, len(boxes)))
+	for b := range t.boxes {
+		t.boxes[b] = []int{}
+		if b < len(boxes) {
+			t.boxes[b] = slices.Clone(boxes[b])
+		}
+	}
+	return nil
+}
+
+// getStorage is the party and the boxes as they are saved, leaving off the empty boxes at the end
+func (t *Trainer) getStorage() (party []int, boxes [][]int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	last := len(t.boxes)
+	for last > 0 && len(t.boxes[last - 1]) == 0 {
+		last--
+	}
+
+	boxes = [][]int{}
+	for _, ids := range t.boxes[:last] {
+		boxes = append(boxes, slices.Clone(ids))
+	}
+	return slices.Clone(t.party), boxes
+}
+
+func (t *Trainer) checkBox(box int) (err error) {
+	if box < 1 || box > len(t.boxes) {
+		return fmt.Errorf("there is no box %d, they go from 1 to %d", box, len(t.boxes))
+	}
+	return nil
+}
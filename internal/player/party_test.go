@@ -0,0 +1,262 @@
+package player
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"testing"
+)
+
+// newTestTrainer is a trainer who has caught count pokemon, with IDs 1 to count
+func newTestTrainer(t *testing.T, count int) (trainer *Trainer) {
+	trainer = NewTrainer()
+	for i := 1; i <= count; i++ {
+		if _, err := trainer.Catch(Pokemon{Species: fmt.Sprintf("species-%d", i)}); err != nil {
+			t.Fatalf("catching pokemon %d: %v", i, err)
+		}
+	}
+	return trainer
+}
+
+// checkStorage fails the test unless every pokemon is kept in exactly one place, within capacity, with the party not empty
+func checkStorage(t *testing.T, trainer *Trainer) {
+	t.Helper()
+	party, boxes := trainer.getStorage()
+	if err := trainer.setStorage(party, boxes, len(trainer.boxes)); err != nil {
+		t.Errorf("storage is broken: %v (party %v, boxes %v)", err, party, boxes)
+	}
+	return
+}
+
+func boxIDs(t *testing.T, trainer *Trainer, box int) (ids []int) {
+	t.Helper()
+	pokemon, err := trainer.GetBox(box)
+	if err != nil {
+		t.Fatalf("GetBox(%d): %v", box, err)
+	}
+	ids = []int{}
+	for _, p := range pokemon {
+		ids = append(ids, p.ID)
+	}
+	return ids
+}
+
+func partyIDs(trainer *Trainer) (ids []int) {
+	ids = []int{}
+	for _, p := range trainer.GetParty() {
+		ids = append(ids, p.ID)
+	}
+	return ids
+}
+
+func TestStoreFillsPartyThenBoxes(t *testing.T) {
+	trainer := newTestTrainer(t, PartySize + BoxCapacity + 2)
+	checkStorage(t, trainer)
+
+	if got, want := partyIDs(trainer), []int{1, 2, 3, 4, 5, 6}; !slices.Equal(got, want) {
+		t.Errorf("party = %v, want %v", got, want)
+	}
+	if got := boxIDs(t, trainer, 1); len(got) != BoxCapacity || got[0] != PartySize + 1 {
+		t.Errorf("box 1 = %v, want the %d pokemon after the party", got, BoxCapacity)
+	}
+	if got, want := boxIDs(t, trainer, 2), []int{37, 38}; !slices.Equal(got, want) {
+		t.Errorf("box 2 = %v, want %v", got, want)
+	}
+}
+
+func TestStoreWhenFull(t *testing.T) {
+	trainer := newTestTrainer(t, PartySize + BoxCount * BoxCapacity)
+	checkStorage(t, trainer)
+
+	if trainer.HasRoom() {
+		t.Error("HasRoom() with the party and every box full")
+	}
+	if _, err := trainer.Catch(Pokemon{Species: "one-too-many"}); !errors.Is(err, ErrStorageFull) {
+		t.Errorf("Catch() = %v, want ErrStorageFull", err)
+	}
+	if trainer.LastID() != PartySize + BoxCount * BoxCapacity || len(trainer.GetAllPokemon()) != PartySize + BoxCount * BoxCapacity {
+		t.Error("a pokemon that didn't fit was still caught")
+	}
+	if _, err := trainer.Deposit(1, 0); err == nil {
+		t.Error("Deposit() found room in full boxes")
+	}
+
+	if _, err := trainer.Release(7); err != nil {
+		t.Fatal(err)
+	}
+	if !trainer.HasRoom() {
+		t.Fatal("no room after releasing a boxed pokemon")
+	}
+	caught, err := trainer.Catch(Pokemon{Species: "fits-now"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if box, _ := trainer.Locate(caught.ID); box != 1 {
+		t.Errorf("the new catch went to box %d, want the free space in box 1", box)
+	}
+	checkStorage(t, trainer)
+}
+
+func TestDeposit(t *testing.T) {
+	tests := []struct {
+		name string
+		caught int
+		id int
+		box int
+		wantBox int
+		wantErr bool
+	}{
+		{"first box with room", 8, 3, 0, 1, false},
+		{"picked box", 8, 3, 5, 5, false},
+		{"skips a full box", PartySize + BoxCapacity, 3, 0, 2, false},
+		{"picked box is full", PartySize + BoxCapacity, 3, 1, 0, true},
+		{"already boxed", 8, 7, 0, 0, true},
+		{"no such pokemon", 8, 99, 0, 0, true},
+		{"no such box", 8, 3, BoxCount + 1, 0, true},
+		{"box 0 isn't a box", 8, 3, -1, 0, true},
+		{"last in the party", 1, 1, 0, 0, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			trainer := newTestTrainer(t, test.caught)
+			before := partyIDs(trainer)
+
+			box, err := trainer.Deposit(test.id, test.box)
+			if test.wantErr {
+				if err == nil {
+					t.Errorf("Deposit(%d, %d) put it in box %d, want an error", test.id, test.box, box)
+				}
+				if !slices.Equal(partyIDs(trainer), before) {
+					t.Error("a failed deposit changed the party")
+				}
+			} else if err != nil {
+				t.Errorf("Deposit(%d, %d): %v", test.id, test.box, err)
+			} else if located, _ := trainer.Locate(test.id); box != test.wantBox || located != test.wantBox {
+				t.Errorf("Deposit(%d, %d) = box %d, located in %d, want %d", test.id, test.box, box, located, test.wantBox)
+			}
+			checkStorage(t, trainer)
+		})
+	}
+}
+
+func TestWithdraw(t *testing.T) {
+	trainer := newTestTrainer(t, 8)
+	if _, err := trainer.Withdraw(7); err == nil {
+		t.Error("Withdraw() into a full party")
+	}
+	if _, err := trainer.Withdraw(3); err == nil {
+		t.Error("Withdraw() of a pokemon already in the party")
+	}
+	if _, err := trainer.Withdraw(99); err == nil {
+		t.Error("Withdraw() of a pokemon that doesn't exist")
+	}
+
+	if _, err := trainer.Deposit(2, 4); err != nil {
+		t.Fatal(err)
+	}
+	from, err := trainer.Withdraw(8)
+	if err != nil || from != 1 {
+		t.Fatalf("Withdraw(8) = %d, %v, want box 1", from, err)
+	}
+	if got, want := partyIDs(trainer), []int{1, 3, 4, 5, 6, 8}; !slices.Equal(got, want) {
+		t.Errorf("party = %v, want %v", got, want)
+	}
+	if got, want := boxIDs(t, trainer, 1), []int{7}; !slices.Equal(got, want) {
+		t.Errorf("box 1 = %v, want %v", got, want)
+	}
+	checkStorage(t, trainer)
+}
+
+func TestSwap(t *testing.T) {
+	tests := []struct {
+		name string
+		first int
+		second int
+		wantParty []int
+		wantBox1 []int
+		wantErr bool
+	}{
+		{"within the party", 1, 6, []int{6, 2, 3, 4, 5, 1}, []int{7, 8, 9}, false},
+		{"within a box", 9, 7, []int{1, 2, 3, 4, 5, 6}, []int{9, 8, 7}, false},
+		{"party and box", 2, 8, []int{1, 8, 3, 4, 5, 6}, []int{7, 2, 9}, false},
+		{"with itself", 2, 2, []int{1, 2, 3, 4, 5, 6}, []int{7, 8, 9}, true},
+		{"first doesn't exist", 99, 2, []int{1, 2, 3, 4, 5, 6}, []int{7, 8, 9}, true},
+		{"second doesn't exist", 2, 99, []int{1, 2, 3, 4, 5, 6}, []int{7, 8, 9}, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			trainer := newTestTrainer(t, 9)
+			err := trainer.Swap(test.first, test.second)
+			if test.wantErr != (err != nil) {
+				t.Errorf("Swap(%d, %d) = %v, want an error: %v", test.first, test.second, err, test.wantErr)
+			}
+			if got := partyIDs(trainer); !slices.Equal(got, test.wantParty) {
+				t.Errorf("party = %v, want %v", got, test.wantParty)
+			}
+			if got := boxIDs(t, trainer, 1); !slices.Equal(got, test.wantBox1) {
+				t.Errorf("box 1 = %v, want %v", got, test.wantBox1)
+			}
+			checkStorage(t, trainer)
+		})
+	}
+}
+
+func TestRelease(t *testing.T) {
+	trainer := newTestTrainer(t, 7)
+	released, err := trainer.Release(7)
+	if err != nil || released.ID != 7 {
+		t.Fatalf("Release(7) = %+v, %v", released, err)
+	}
+	if _, isFound := trainer.GetPokemon(7); isFound {
+		t.Error("a released pokemon is still there")
+	}
+	if _, isFound := trainer.GetDexEntry(released.Species); !isFound {
+		t.Error("releasing a pokemon took its species out of the pokedex")
+	}
+	if _, err = trainer.Release(7); err == nil {
+		t.Error("released the same pokemon twice")
+	}
+
+	for id := 2; id <= PartySize; id++ {
+		if _, err = trainer.Release(id); err != nil {
+			t.Fatalf("Release(%d): %v", id, err)
+		}
+	}
+	if _, err = trainer.Release(1); err == nil {
+		t.Error("released the last pokemon in the party")
+	}
+	if got := partyIDs(trainer); !slices.Equal(got, []int{1}) {
+		t.Errorf("party = %v, want [1]", got)
+	}
+	checkStorage(t, trainer)
+
+	// IDs are never reused, even after a release
+	if caught, _ := trainer.Catch(Pokemon{Species: "new"}); caught.ID != 8 {
+		t.Errorf("the next catch got ID %d, want 8", caught.ID)
+	}
+}
+
+func TestPartyNeverEmpty(t *testing.T) {
+	trainer := newTestTrainer(t, 8)
+	for _, id := range []int{1, 2, 3, 4, 5} {
+		if _, err := trainer.Deposit(id, 0); err != nil {
+			t.Fatalf("Deposit(%d): %v", id, err)
+		}
+	}
+
+	if _, err := trainer.Deposit(6, 0); err == nil {
+		t.Error("deposited the last pokemon in the party")
+	}
+	if _, err := trainer.Release(6); err == nil {
+		t.Error("released the last pokemon in the party")
+	}
+	if err := trainer.Swap(6, 7); err != nil {
+		t.Errorf("Swap(6, 7): %v", err)
+	}
+	if got := partyIDs(trainer); !slices.Equal(got, []int{7}) {
+		t.Errorf("party = %v, want [7]", got)
+	}
+	checkStorage(t, trainer)
+}
@@ -0,0 +1,319 @@
+package player
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+	"github.com/CRowland4/pokedexcli/internal/game"
+)
+/*-------------------------------------------------------------------------------------------------------------------------------------------------------------------*/
+
+// StartingBag is what a new trainer carries, and what trainers from saves made before there was a bag are given
+var StartingBag = []BagItem{
+	{Item: "poke-ball", Count: 10},
+	{Item: "great-ball", Count: 3},
+}
+
+/*-------------------------------------------------------------------------------------------------------------------------------------------------------------------*/
+
+// Trainer is the player: everything they own that has to outlive a session. Species data stays in pokecache and is looked up by name.
+type Trainer struct{
+	mu *sync.Mutex
+	pokedex []CaughtPokemon  // One entry per species ever caught
+	pokemon []Pokemon  // Every pokemon the trainer has, in the order they were caught
+	party []int  // IDs of the pokemon in the party, in order
+	boxes [][]int  // IDs of the pokemon in each PC box, BoxCount of them plus any extra an old save needed
+	lastID int  // The ID of the last pokemon caught, so IDs are never reused
+	bag []BagItem  // In the order the items were first picked up
+	seed int64  // Random seed of the session that last saved, so it can be replayed
+	gameVersion string  // The game version whose areas and encounters the trainer plays, or "" for all of them
+}
+
+// CaughtPokemon is the pokedex entry of a species, from when it was first caught
+type CaughtPokemon struct{
+	Species string `json:"species"`
+	Nickname string `json:"nickname,omitempty"`  // Only before save version 4, when nicknames moved to each Pokemon
+	CaughtAt time.Time `json:"caught_at"`
+	Location string `json:"location,omitempty"`
+}
+
+// Pokemon is one caught pokemon, told apart from others of its species by its ID. Pokemon from saves made before
+// there were individual pokemon only know what the pokedex did, so their level is 0 and their IVs are nil.
+type Pokemon struct{
+	ID int `json:"id"`
+	Species string `json:"species"`
+	Nickname string `json:"nickname,omitempty"`
+	Level int `json:"level,omitempty"`
+	IVs *game.Stats `json:"ivs,omitempty"`
+	Nature game.Nature `json:"nature,omitempty"`
+	Gender game.Gender `json:"gender,omitempty"`
+	IsShiny bool `json:"shiny,omitempty"`
+	Ball string `json:"ball,omitempty"`
+	CaughtAt time.Time `json:"caught_at"`
+	Location string `json:"location,omitempty"`
+}
+
+// BagItem is how many of one item, by its PokeAPI name, the trainer carries. Used up items stay with a count of 0.
+type BagItem struct{
+	Item string `json:"item"`
+	Count int `json:"count"`
+}
+
+/*-------------------------------------------------------------------------------------------------------------------------------------------------------------------*/
+
+// Catch gives a newly caught pokemon the next ID and the time, puts it in the party or a box with room, and adds its
+// species to the pokedex if it's new there. Catching a species again keeps the original pokedex entry.
+func (t *Trainer) Catch(pokemon Pokemon) (caught Pokemon, err error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if err = t.store(t.lastID + 1); err != nil {
+		return caught, err
+	}
+	t.lastID++
+	pokemon.ID = t.lastID
+	pokemon.CaughtAt = time.Now()
+	t.pokemon = append(t.pokemon, pokemon)
+
+	if t.indexOf(pokemon.Species) == -1 {
+		t.pokedex = append(t.pokedex, CaughtPokemon{
+			Species: pokemon.Species,
+			CaughtAt: pokemon.CaughtAt,
+			Location: pokemon.Location,
+		})
+	}
+	return pokemon, nil
+}
+
+func (t *Trainer) SetSeed(seed int64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.seed = seed
+	return
+}
+
+func (t *Trainer) Seed() (seed int64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.seed
+}
+
+// SetGameVersion picks the game version, by its PokeAPI name, to play. "" plays every version.
+func (t *Trainer) SetGameVersion(version string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.gameVersion = version
+	return
+}
+
+func (t *Trainer) GameVersion() (version string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.gameVersion
+}
+
+func (t *Trainer) GetCaughtPokemon() (caughtPokemon []CaughtPokemon) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return slices.Clone(t.pokedex)
+}
+
+func (t *Trainer) GetDexEntry(species string) (entry CaughtPokemon, isFound bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if i := t.indexOf(species); i != -1 {
+		return t.pokedex[i], true
+	}
+
+	return entry, false
+}
+
+func (t *Trainer) LastID() (id int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.lastID
+}
+
+func (t *Trainer) GetAllPokemon() (pokemon []Pokemon) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return slices.Clone(t.pokemon)
+}
+
+func (t *Trainer) GetPokemon(id int) (pokemon Pokemon, isFound bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if i := t.pokemonIndexOf(id); i != -1 {
+		return t.pokemon[i], true
+	}
+
+	return pokemon, false
+}
+
+// FindPokemon is every pokemon the trainer has with the nickname or, failing that, of the species
+func (t *Trainer) FindPokemon(name string) (matches []Pokemon) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for _, pokemon := range t.pokemon {
+		if pokemon.Nickname == name {
+			matches = append(matches, pokemon)
+		}
+	}
+	if len(matches) > 0 {
+		return matches
+	}
+
+	for _, pokemon := range t.pokemon {
+		if pokemon.Species == name {
+			matches = append(matches, pokemon)
+		}
+	}
+	return matches
+}
+
+// SetNickname names one of the trainer's pokemon; an empty nickname clears it
+func (t *Trainer) SetNickname(id int, nickname string) (err error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	i := t.pokemonIndexOf(id)
+	if i == -1 {
+		return fmt.Errorf("you don't have a pokemon with ID %d", id)
+	}
+
+	t.pokemon[i].Nickname = nickname
+	return nil
+}
+
+func (t *Trainer) GetBag() (bag []BagItem) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return slices.Clone(t.bag)
+}
+
+func (t *Trainer) ItemCount(item string) (count int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if i := t.bagIndexOf(item); i != -1 {
+		return t.bag[i].Count
+	}
+	return 0
+}
+
+func (t *Trainer) AddItem(item string, count int) (err error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.addItem(item, count)
+}
+
+// UseItem takes one of the item out of the bag
+func (t *Trainer) UseItem(item string) (err error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	i := t.bagIndexOf(item)
+	if i == -1 || t.bag[i].Count == 0 {
+		return fmt.Errorf("no %s left in the bag", item)
+	}
+
+	t.bag[i].Count--
+	return nil
+}
+
+func (t *Trainer) indexOf(species string) (index int) {
+	return slices.IndexFunc(t.pokedex, func(pokemon CaughtPokemon) bool {
+		return pokemon.Species == species
+	})
+}
+
+func (t *Trainer) pokemonIndexOf(id int) (index int) {
+	return slices.IndexFunc(t.pokemon, func(pokemon Pokemon) bool {
+		return pokemon.ID == id
+	})
+}
+
+func (t *Trainer) bagIndexOf(item string) (index int) {
+	return slices.IndexFunc(t.bag, func(bagItem BagItem) bool {
+		return bagItem.Item == item
+	})
+}
+
+func (t *Trainer) addItem(item string, count int) (err error) {
+	if item == "" {
+		return errors.New("bag item without a name")
+	} else if count < 0 {
+		return fmt.Errorf("%d is not a number of %s", count, item)
+	}
+
+	if i := t.bagIndexOf(item); i != -1 {
+		t.bag[i].Count += count
+	} else {
+		t.bag = append(t.bag, BagItem{Item: item, Count: count})
+	}
+	return nil
+}
+
+func (t *Trainer) add(pokemon CaughtPokemon) (err error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if pokemon.Species == "" {
+		return errors.New("caught pokemon without a species")
+	} else if t.indexOf(pokemon.Species) != -1 {
+		return fmt.Errorf("%s is caught twice", pokemon.Species)
+	}
+
+	t.pokedex = append(t.pokedex, pokemon)
+	return nil
+}
+
+func (t *Trainer) addPokemon(pokemon Pokemon) (err error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if pokemon.ID < 1 {
+		return fmt.Errorf("%s has no ID", pokemon.Species)
+	} else if pokemon.Species == "" {
+		return fmt.Errorf("pokemon %d has no species", pokemon.ID)
+	} else if t.pokemonIndexOf(pokemon.ID) != -1 {
+		return fmt.Errorf("two pokemon have ID %d", pokemon.ID)
+	} else if pokemon.Level < 0 || pokemon.Level > game.MaxLevel {
+		return fmt.Errorf("%s %d is level %d", pokemon.Species, pokemon.ID, pokemon.Level)
+	} else if pokemon.IVs != nil {
+		if err = pokemon.IVs.ValidateIVs(); err != nil {
+			return fmt.Errorf("%s %d: %w", pokemon.Species, pokemon.ID, err)
+		}
+	}
+	if pokemon.Nature != "" {
+		if _, err = game.ParseNature(string(pokemon.Nature)); err != nil {
+			return fmt.Errorf("%s %d: %w", pokemon.Species, pokemon.ID, err)
+		}
+	}
+
+	t.pokemon = append(t.pokemon, pokemon)
+	t.lastID = max(t.lastID, pokemon.ID)
+	return nil
+}
+
+/*==================================================================================================================================*/
+
+func NewTrainer() (trainer *Trainer) {
+	trainer = &Trainer{
+		mu: new(sync.Mutex),
+		pokedex: []CaughtPokemon{},
+		pokemon: []Pokemon{},
+		party: []int{},
+		boxes: make([][]int, BoxCount),
+		bag: slices.Clone(StartingBag),
+	}
+	for i := range trainer.boxes {
+		trainer.boxes[i] = []int{}
+	}
+	return trainer
+}
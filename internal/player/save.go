@@ -0,0 +1,181 @@
+package player
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+)
+/*-------------------------------------------------------------------------------------------------------------------------------------------------------------------*/
+
+// SaveVersion is bumped whenever the save file layout changes, so older files can still be read.
+// Version 1 only had the names of caught pokemon, version 2 had no bag, version 3 had one pokedex entry per species
+// instead of individual pokemon, and version 4 had no party or boxes.
+const SaveVersion = 5
+
+/*-------------------------------------------------------------------------------------------------------------------------------------------------------------------*/
+
+type saveFile struct{
+	Version int `json:"version"`
+	SavedAt time.Time `json:"saved_at"`
+	Seed int64 `json:"seed,omitempty"`
+	GameVersion string `json:"game_version,omitempty"`
+	Pokedex []CaughtPokemon `json:"pokedex"`
+	Pokemon []Pokemon `json:"pokemon"`
+	LastID int `json:"last_id"`
+	Party []int `json:"party"`
+	Boxes [][]int `json:"boxes"`
+	ExtraBoxes int `json:"extra_boxes,omitempty"`  // How many boxes past BoxCount are left over from a version 4 save
+	Bag []BagItem `json:"bag"`
+	Caught []string `json:"caught,omitempty"`  // Version 1 only
+}
+
+/*-------------------------------------------------------------------------------------------------------------------------------------------------------------------*/
+
+// Save writes the trainer to path through a temporary file and a rename, so a crash part way through leaves the previous save intact
+func Save(path string, trainer *Trainer) (err error) {
+	party, boxes := trainer.getStorage()
+	data, err := json.MarshalIndent(saveFile{
+		Version: SaveVersion,
+		SavedAt: time.Now(),
+		Seed: trainer.Seed(),
+		GameVersion: trainer.GameVersion(),
+		Pokedex: trainer.GetCaughtPokemon(),
+		Pokemon: trainer.GetAllPokemon(),
+		LastID: trainer.LastID(),
+		Party: party,
+		Boxes: boxes,
+		ExtraBoxes: max(len(boxes) - BoxCount, 0),
+		Bag: trainer.GetBag(),
+	}, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err = os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+
+	temp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path) + ".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(temp.Name())
+
+	if _, err = temp.Write(data); err != nil {
+		temp.Close()
+		return err
+	}
+	if err = temp.Sync(); err != nil {
+		temp.Close()
+		return err
+	}
+	if err = temp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(temp.Name(), path)
+}
+
+// Load reads the save file at path. A file that doesn't exist yet is a new trainer, not an error.
+func Load(path string) (trainer *Trainer, err error) {
+	trainer = NewTrainer()
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return trainer, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	var save saveFile
+	if err = json.Unmarshal(data, &save); err != nil {
+		return nil, fmt.Errorf("%s is not a valid save file: %w", path, err)
+	}
+
+	if save.Version > SaveVersion {
+		return nil, fmt.Errorf("%s was saved by a newer pokedexcli (save version %d, this one reads up to %d)", path, save.Version, SaveVersion)
+	}
+
+	if save.Version <= 1 {
+		for _, name := range save.Caught {
+			save.Pokedex = append(save.Pokedex, CaughtPokemon{Species: name})
+		}
+	}
+
+	// Every species caught becomes one pokemon, with its nickname
+	if save.Version <= 3 {
+		for i := range save.Pokedex {
+			save.Pokemon = append(save.Pokemon, Pokemon{
+				ID: i + 1,
+				Species: save.Pokedex[i].Species,
+				Nickname: save.Pokedex[i].Nickname,
+				CaughtAt: save.Pokedex[i].CaughtAt,
+				Location: save.Pokedex[i].Location,
+			})
+			save.Pokedex[i].Nickname = ""
+		}
+	}
+
+	for _, pokemon := range save.Pokedex {
+		if err = trainer.add(pokemon); err != nil {
+			return nil, fmt.Errorf("%s is not a valid save file: %w", path, err)
+		}
+	}
+	for _, pokemon := range save.Pokemon {
+		if err = trainer.addPokemon(pokemon); err != nil {
+			return nil, fmt.Errorf("%s is not a valid save file: %w", path, err)
+		}
+	}
+	trainer.lastID = max(trainer.lastID, save.LastID)
+
+	// The first pokemon caught make up the party and the rest fill the boxes in order, past BoxCount if that's what it takes
+	boxCount := BoxCount + max(save.ExtraBoxes, 0)
+	if save.Version <= 4 {
+		save.Party, save.Boxes = []int{}, [][]int{}
+		for i, pokemon := range save.Pokemon {
+			if i < PartySize {
+				save.Party = append(save.Party, pokemon.ID)
+			} else if box := (i - PartySize) / BoxCapacity; box < len(save.Boxes) {
+				save.Boxes[box] = append(save.Boxes[box], pokemon.ID)
+			} else {
+				save.Boxes = append(save.Boxes, []int{pokemon.ID})
+			}
+		}
+		boxCount = max(BoxCount, len(save.Boxes))
+	}
+	if err = trainer.setStorage(save.Party, save.Boxes, boxCount); err != nil {
+		return nil, fmt.Errorf("%s is not a valid save file: %w", path, err)
+	}
+
+	if save.Version <= 2 {
+		save.Bag = StartingBag
+	}
+	trainer.bag = []BagItem{}
+	for _, item := range save.Bag {
+		if err = trainer.AddItem(item.Item, item.Count); err != nil {
+			return nil, fmt.Errorf("%s is not a valid save file: %w", path, err)
+		}
+	}
+
+	trainer.seed = save.Seed
+	trainer.gameVersion = save.GameVersion
+	return trainer, nil
+}
+
+// DefaultSavePath is save.json in $XDG_DATA_HOME/pokedexcli, or ~/.local/share/pokedexcli when XDG_DATA_HOME is unset
+func DefaultSavePath() (path string, err error) {
+	dataHome := os.Getenv("XDG_DATA_HOME")
+	if dataHome == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		dataHome = filepath.Join(home, ".local", "share")
+	}
+
+	return filepath.Join(dataHome, "pokedexcli", "save.json"), nil
+}
@@ -0,0 +1,350 @@
+package player
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+	"github.com/CRowland4/pokedexcli/internal/game"
+)
+
+func writeSave(t *testing.T, contents string) (path string) {
+	path = filepath.Join(t.TempDir(), "save.json")
+	if err := os.WriteFile(path, []byte(contents), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadMigratesOldSaves(t *testing.T) {
+	tests := []struct {
+		name string
+		save string
+		wantDex []string
+		wantPokemon []string  // Species, in ID order from 1
+		wantNicknames map[int]string
+		wantParty []int
+		wantBoxes [][]int
+		wantBag []BagItem
+	}{
+		{
+			name: "version 1",
+			save: `{"version": 1, "caught": ["pidgey", "rattata"]}`,
+			wantDex: []string{"pidgey", "rattata"},
+			wantPokemon: []string{"pidgey", "rattata"},
+			wantParty: []int{1, 2},
+			wantBag: StartingBag,
+		},
+		{
+			name: "version 3",
+			save: `{"version": 3, "pokedex": [
+				{"species": "pidgey", "nickname": "Pidge", "caught_at": "2024-01-01T00:00:00Z", "location": "route-1"},
+				{"species": "rattata"}
+			], "bag": [{"item": "ultra-ball", "count": 2}]}`,
+			wantDex: []string{"pidgey", "rattata"},
+			wantPokemon: []string{"pidgey", "rattata"},
+			wantNicknames: map[int]string{1: "Pidge"},
+			wantParty: []int{1, 2},
+			wantBag: []BagItem{{Item: "ultra-ball", Count: 2}},
+		},
+		{
+			name: "version 4",
+			save: `{"version": 4, "pokedex": [{"species": "pidgey"}, {"species": "rattata"}],
+				"pokemon": [
+					{"id": 1, "species": "pidgey"}, {"id": 2, "species": "pidgey"}, {"id": 4, "species": "rattata"},
+					{"id": 5, "species": "pidgey"}, {"id": 6, "species": "pidgey"}, {"id": 7, "species": "pidgey"},
+					{"id": 9, "species": "rattata", "nickname": "Rat"}, {"id": 10, "species": "pidgey"}
+				], "last_id": 12, "bag": []}`,
+			wantDex: []string{"pidgey", "rattata"},
+			wantPokemon: []string{"pidgey", "pidgey", "rattata", "pidgey", "pidgey", "pidgey", "rattata", "pidgey"},
+			wantNicknames: map[int]string{9: "Rat"},
+			wantParty: []int{1, 2, 4, 5, 6, 7},
+			wantBoxes: [][]int{{9, 10}},
+			wantBag: []BagItem{},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			trainer, err := Load(writeSave(t, test.save))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			dex := []string{}
+			for _, entry := range trainer.GetCaughtPokemon() {
+				dex = append(dex, entry.Species)
+				if entry.Nickname != "" {
+					t.Errorf("the %s pokedex entry kept its nickname", entry.Species)
+				}
+			}
+			if !slices.Equal(dex, test.wantDex) {
+				t.Errorf("pokedex = %v, want %v", dex, test.wantDex)
+			}
+
+			species := []string{}
+			for _, pokemon := range trainer.GetAllPokemon() {
+				species = append(species, pokemon.Species)
+				if pokemon.Nickname != test.wantNicknames[pokemon.ID] {
+					t.Errorf("pokemon %d is nicknamed %q, want %q", pokemon.ID, pokemon.Nickname, test.wantNicknames[pokemon.ID])
+				}
+			}
+			if !slices.Equal(species, test.wantPokemon) {
+				t.Errorf("pokemon = %v, want %v", species, test.wantPokemon)
+			}
+
+			party, boxes := trainer.getStorage()
+			if !slices.Equal(party, test.wantParty) {
+				t.Errorf("party = %v, want %v", party, test.wantParty)
+			}
+			if !slices.EqualFunc(boxes, test.wantBoxes, slices.Equal[[]int]) {
+				t.Errorf("boxes = %v, want %v", boxes, test.wantBoxes)
+			}
+			if !slices.Equal(trainer.GetBag(), test.wantBag) {
+				t.Errorf("bag = %v, want %v", trainer.GetBag(), test.wantBag)
+			}
+			checkStorage(t, trainer)
+		})
+	}
+}
+
+func TestLoadMigratedIDsAreNotReused(t *testing.T) {
+	trainer, err := Load(writeSave(t, `{"version": 4, "pokedex": [{"species": "pidgey"}],
+		"pokemon": [{"id": 1, "species": "pidgey"}, {"id": 3, "species": "pidgey"}], "last_id": 5}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if caught, _ := trainer.Catch(Pokemon{Species: "pidgey"}); caught.ID != 6 {
+		t.Errorf("the next catch got ID %d, want 6", caught.ID)
+	}
+}
+
+func TestLoadRejectsBadSaves(t *testing.T) {
+	const pokemon = `"pokedex": [{"species": "pidgey"}], "pokemon": [{"id": 1, "species": "pidgey"}, {"id": 2, "species": "pidgey"}]`
+	tests := []struct {
+		name string
+		save string
+		wantErr string
+	}{
+		{"not JSON", `{"version": 5,`, "not a valid save file"},
+		{"newer version", `{"version": 6}`, "newer pokedexcli"},
+		{"species caught twice", `{"version": 5, "pokedex": [{"species": "pidgey"}, {"species": "pidgey"}]}`, "pidgey is caught twice"},
+		{"duplicate ID", `{"version": 5, "pokedex": [{"species": "pidgey"}], "pokemon": [{"id": 1, "species": "pidgey"}, {"id": 1, "species": "pidgey"}], "party": [1]}`, "two pokemon have ID 1"},
+		{"no ID", `{"version": 5, "pokemon": [{"species": "pidgey"}], "party": [0]}`, "pidgey has no ID"},
+		{"no species", `{"version": 5, "pokemon": [{"id": 1}], "party": [1]}`, "pokemon 1 has no species"},
+		{"level too high", `{"version": 5, "pokemon": [{"id": 1, "species": "pidgey", "level": 101}], "party": [1]}`, "is level 101"},
+		{"unknown nature", `{"version": 5, "pokemon": [{"id": 1, "species": "pidgey", "nature": "grumpy"}], "party": [1]}`, "pidgey 1"},
+		{"unknown ID in the party", `{"version": 5, ` + pokemon + `, "party": [1, 2, 3]}`, "there's no pokemon 3 to keep"},
+		{"unknown ID in a box", `{"version": 5, ` + pokemon + `, "party": [1, 2], "boxes": [[3]]}`, "there's no pokemon 3 to keep"},
+		{"ID in the party twice", `{"version": 5, ` + pokemon + `, "party": [1, 2, 1]}`, "pokemon 1 is kept in two places"},
+		{"ID in the party and a box", `{"version": 5, ` + pokemon + `, "party": [1, 2], "boxes": [[], [2]]}`, "pokemon 2 is kept in two places"},
+		{"pokemon kept nowhere", `{"version": 5, ` + pokemon + `, "party": [1]}`, "aren't in the party or any box"},
+		{"empty party", `{"version": 5, ` + pokemon + `, "party": [], "boxes": [[1, 2]]}`, "the party is empty"},
+		{"party too big", `{"version": 5, "pokedex": [{"species": "a"}], "pokemon": [{"id": 1, "species": "a"}, {"id": 2, "species": "a"}, {"id": 3, "species": "a"}, {"id": 4, "species": "a"}, {"id": 5, "species": "a"}, {"id": 6, "species": "a"}, {"id": 7, "species": "a"}], "party": [1, 2, 3, 4, 5, 6, 7]}`, "more than 6"},
+		{"negative bag count", `{"version": 5, "bag": [{"item": "poke-ball", "count": -1}]}`, "not a number of poke-ball"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			trainer, err := Load(writeSave(t, test.save))
+			if err == nil {
+				t.Fatalf("Load() = %+v, want an error", trainer)
+			} else if !strings.Contains(err.Error(), test.wantErr) {
+				t.Errorf("Load() error = %q, want it to mention %q", err, test.wantErr)
+			}
+		})
+	}
+}
+
+func TestLoadRejectsOverfullBox(t *testing.T) {
+	save := saveFile{Version: SaveVersion, Pokedex: []CaughtPokemon{{Species: "pidgey"}}, Party: []int{1}, Boxes: [][]int{{}}}
+	for id := 1; id <= 1 + BoxCapacity + 1; id++ {
+		save.Pokemon = append(save.Pokemon, Pokemon{ID: id, Species: "pidgey"})
+		if id > 1 {
+			save.Boxes[0] = append(save.Boxes[0], id)
+		}
+	}
+	data, err := json.Marshal(save)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = Load(writeSave(t, string(data))); err == nil || !strings.Contains(err.Error(), "box 1 has 31 pokemon") {
+		t.Errorf("Load() error = %v, want box 1 over capacity", err)
+	}
+}
+
+func TestLoadMissingFile(t *testing.T) {
+	trainer, err := Load(filepath.Join(t.TempDir(), "new", "save.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(trainer.GetAllPokemon()) != 0 || len(trainer.GetParty()) != 0 || !slices.Equal(trainer.GetBag(), StartingBag) {
+		t.Errorf("a missing save isn't a new trainer: %+v", trainer)
+	}
+}
+
+func TestSaveLoadRoundTrip(t *testing.T) {
+	trainer := newTestTrainer(t, PartySize + BoxCapacity + 3)
+	ivs := game.Stats{HP: 31, Attack: 0, Defense: 15, SpecialAttack: 7, SpecialDefense: 20, Speed: 31}
+	shiny, err := trainer.Catch(Pokemon{Species: "pikachu", Nickname: "Sparky", Level: 12, IVs: &ivs, Nature: "jolly",
+		Gender: game.GenderFemale, IsShiny: true, Ball: "great-ball", Location: "viridian-forest-area"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = trainer.Deposit(2, 5); err != nil {
+		t.Fatal(err)
+	}
+	if err = trainer.Swap(1, PartySize + 1); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = trainer.Release(10); err != nil {
+		t.Fatal(err)
+	}
+	if err = trainer.UseItem("poke-ball"); err != nil {
+		t.Fatal(err)
+	}
+	if err = trainer.AddItem("ultra-ball", 4); err != nil {
+		t.Fatal(err)
+	}
+	trainer.SetSeed(42)
+	trainer.SetGameVersion("red")
+
+	path := filepath.Join(t.TempDir(), "nested", "save.json")
+	if err = Save(path, trainer); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !slices.EqualFunc(loaded.GetAllPokemon(), trainer.GetAllPokemon(), samePokemon) {
+		t.Errorf("pokemon = %+v, want %+v", loaded.GetAllPokemon(), trainer.GetAllPokemon())
+	}
+	if got, _ := loaded.GetPokemon(shiny.ID); got.IVs == nil || *got.IVs != ivs {
+		t.Errorf("%s has IVs %v, want %v", got.Species, got.IVs, ivs)
+	}
+	if !slices.EqualFunc(loaded.GetCaughtPokemon(), trainer.GetCaughtPokemon(), func(a, b CaughtPokemon) bool {
+		return a.Species == b.Species && a.CaughtAt.Equal(b.CaughtAt) && a.Location == b.Location
+	}) {
+		t.Errorf("pokedex = %+v, want %+v", loaded.GetCaughtPokemon(), trainer.GetCaughtPokemon())
+	}
+
+	party, boxes := trainer.getStorage()
+	loadedParty, loadedBoxes := loaded.getStorage()
+	if !slices.Equal(loadedParty, party) || !slices.EqualFunc(loadedBoxes, boxes, slices.Equal[[]int]) {
+		t.Errorf("storage = %v %v, want %v %v", loadedParty, loadedBoxes, party, boxes)
+	}
+	if !slices.Equal(loaded.GetBag(), trainer.GetBag()) {
+		t.Errorf("bag = %v, want %v", loaded.GetBag(), trainer.GetBag())
+	}
+	if loaded.LastID() != trainer.LastID() || loaded.Seed() != 42 || loaded.GameVersion() != "red" {
+		t.Errorf("last ID %d, seed %d, version %q, want %d, 42, red", loaded.LastID(), loaded.Seed(), loaded.GameVersion(), trainer.LastID())
+	}
+
+	entries, err := os.ReadDir(filepath.Dir(path))
+	if err != nil || len(entries) != 1 {
+		t.Errorf("the save directory has %v, want only the save file", entries)
+	}
+}
+
+// samePokemon compares the IVs rather than their pointers, and the times by instant since a save drops the monotonic clock
+func samePokemon(a Pokemon, b Pokemon) (isSame bool) {
+	aIVs, bIVs, aCaughtAt, bCaughtAt := a.IVs, b.IVs, a.CaughtAt, b.CaughtAt
+	a.IVs, b.IVs = nil, nil
+	a.CaughtAt, b.CaughtAt = time.Time{}, time.Time{}
+	return a == b && aCaughtAt.Equal(bCaughtAt) && (aIVs == nil) == (bIVs == nil) && (aIVs == nil || *aIVs == *bIVs)
+}
+
+func TestLoadRejectsTooManyBoxes(t *testing.T) {
+	save := saveFile{Version: SaveVersion, Pokedex: []CaughtPokemon{{Species: "pidgey"}}, Pokemon: []Pokemon{{ID: 1, Species: "pidgey"}, {ID: 2, Species: "pidgey"}},
+		Party: []int{1}, Boxes: make([][]int, BoxCount + 1)}
+	save.Boxes[BoxCount] = []int{2}
+	data, err := json.Marshal(save)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = Load(writeSave(t, string(data))); err == nil || !strings.Contains(err.Error(), "there are 19 boxes, more than 18") {
+		t.Errorf("Load() error = %v, want too many boxes", err)
+	}
+}
+
+// A version 4 save can have more pokemon than the party and boxes hold. They go in extra boxes, which survive saving
+// until they're emptied, but never take new pokemon.
+func TestLoadOldSaveNeedingExtraBoxes(t *testing.T) {
+	const count = PartySize + (BoxCount + 1) * BoxCapacity + 5
+	save := saveFile{Version: 4, Pokedex: []CaughtPokemon{{Species: "pidgey"}}}
+	for id := 1; id <= count; id++ {
+		save.Pokemon = append(save.Pokemon, Pokemon{ID: id, Species: "pidgey"})
+	}
+	data, err := json.Marshal(save)
+	if err != nil {
+		t.Fatal(err)
+	}
+	trainer, err := Load(writeSave(t, string(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got, want := boxIDs(t, trainer, BoxCount + 2), []int{count - 4, count - 3, count - 2, count - 1, count}; !slices.Equal(got, want) {
+		t.Errorf("box %d = %v, want %v", BoxCount + 2, got, want)
+	}
+	if _, err = trainer.GetBox(BoxCount + 3); err == nil {
+		t.Errorf("there's a box %d past the ones the save needed", BoxCount + 3)
+	}
+	if trainer.HasRoom() {
+		t.Error("HasRoom() with only the extra boxes having space")
+	}
+	if _, err = trainer.Catch(Pokemon{Species: "pidgey"}); !errors.Is(err, ErrStorageFull) {
+		t.Errorf("Catch() = %v, want ErrStorageFull", err)
+	}
+	if _, err = trainer.Deposit(1, BoxCount + 2); err == nil {
+		t.Error("deposited into an extra box")
+	}
+	if _, err = trainer.Release(count); err != nil {
+		t.Fatal(err)
+	}
+	if err = trainer.Swap(1, count - 1); err != nil {
+		t.Errorf("Swap() with an extra box: %v", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "save.json")
+	if err = Save(path, trainer); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := Load(path)
+	if err != nil {
+		t.Fatalf("the save with extra boxes doesn't load again: %v", err)
+	}
+	if got, want := boxIDs(t, loaded, BoxCount + 2), []int{count - 4, count - 3, count - 2, 1}; !slices.Equal(got, want) {
+		t.Errorf("box %d = %v after loading again, want %v", BoxCount + 2, got, want)
+	}
+
+	// Once the extra boxes are emptied they're gone
+	for _, id := range []int{count - 4, count - 3, count - 2, 1} {
+		if _, err = loaded.Release(id); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for box := 1; box <= BoxCapacity; box++ {
+		if _, err = loaded.Release(PartySize + BoxCount * BoxCapacity + box); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err = Save(path, loaded); err != nil {
+		t.Fatal(err)
+	}
+	if loaded, err = Load(path); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = loaded.GetBox(BoxCount + 1); err == nil {
+		t.Errorf("box %d is still there after it was emptied", BoxCount + 1)
+	}
+}
@@ -0,0 +1,179 @@
+package pokeapi
+
+import (
+	"context"
+	"net/http"
+	"fmt"
+	"encoding/json"
+	"io"
+	"strings"
+	"time"
+	"github.com/CRowland4/pokedexcli/internal/pokecache"
+)
+
+const (
+	DefaultBaseURL = "https://pokeapi.co/api/v2"
+	DefaultTimeout = 10 * time.Second
+	DefaultUserAgent = "pokedexcli"
+	DefaultRequestsPerSecond = 10
+	DefaultBurst = 5
+	DefaultMaxConcurrency = 8
+	BaseURLEnvVar = "POKEDEXCLI_API_URL"
+)
+
+/*-------------------------------------------------------------------------------------------------------------------------------------------------------------------*/
+
+// Client talks to a PokeAPI instance, which can be the public API, a self-hosted mirror, or a test server
+type Client struct {
+	baseURL string
+	httpClient *http.Client
+	userAgent string
+	retry RetryPolicy
+	limiter *rateLimiter
+	requestSlots chan struct{}
+	maxConcurrency int
+	diskCache *pokecache.DiskCache
+	inFlight flightGroup
+}
+
+// ClientConfig holds the options for NewClient. Zero values fall back to the defaults above.
+// If HTTPClient is set it is used as-is, and Timeout is ignored. A negative RequestsPerSecond turns rate limiting off.
+type ClientConfig struct {
+	BaseURL string
+	HTTPClient *http.Client
+	Timeout time.Duration
+	UserAgent string
+	Retry RetryPolicy
+	RequestsPerSecond float64
+	Burst int
+	MaxConcurrency int  // Cap on simultaneous in-flight requests, and on workers per fetch fan-out
+	DiskCache *pokecache.DiskCache  // Consulted before the network when set
+}
+
+/*-------------------------------------------------------------------------------------------------------------------------------------------------------------------*/
+
+func (c *Client) resourceURL(format string, args ...any) (address string) {
+	return c.baseURL + fmt.Sprintf(format, args...)
+}
+
+// getJSON decodes the resource at address into target, from the disk cache if it has it and from the network otherwise
+func (c *Client) getJSON(ctx context.Context, address string, target any) (err error) {
+	if body, ok := c.diskCache.Get(address); ok && json.Unmarshal(body, target) == nil {
+		return nil
+	}
+
+	body, err := c.inFlight.do(ctx, address, func() ([]byte, error) {
+		return c.getBody(ctx, address)
+	})
+	if err != nil {
+		return err
+	}
+
+	errUnmarshal := json.Unmarshal(body, target)
+	if errUnmarshal != nil {
+		return &DecodeError{URL: address, Err: errUnmarshal}
+	}
+
+	c.diskCache.Set(address, body)  // A failed write only means the next session downloads this again
+	return nil
+}
+
+func (c *Client) getBody(ctx context.Context, address string) (body []byte, err error) {
+	for attempt := 1; ; attempt++ {
+		body, err = c.fetchBody(ctx, address)
+		if err == nil || ctx.Err() != nil || !isRetryable(err) || attempt >= c.retry.MaxAttempts {
+			return body, err
+		}
+
+		timer := time.NewTimer(c.retry.delay(attempt, err))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+func (c *Client) fetchBody(ctx context.Context, address string) (body []byte, err error) {
+	select {
+	case c.requestSlots <- struct{}{}:
+		defer func() { <-c.requestSlots }()
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	if errWait := c.limiter.wait(ctx); errWait != nil {
+		return nil, errWait
+	}
+
+	request, errRequest := http.NewRequestWithContext(ctx, http.MethodGet, address, nil)
+	if errRequest != nil {
+		return nil, &NetworkError{URL: address, Err: errRequest}
+	}
+	request.Header.Set("User-Agent", c.userAgent)
+	request.Header.Set("Accept", "application/json")
+
+	response, errResponse := c.httpClient.Do(request)
+	if errResponse != nil {
+		return nil, &NetworkError{URL: address, Err: errResponse}
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode == http.StatusNotFound {
+		return nil, &NotFoundError{URL: address}
+	} else if response.StatusCode != http.StatusOK {
+		return nil, &StatusError{
+			URL: address,
+			StatusCode: response.StatusCode,
+			RetryAfter: parseRetryAfter(response.Header.Get("Retry-After")),
+		}
+	}
+
+	body, errBody := io.ReadAll(response.Body)
+	if errBody != nil {
+		return nil, &DecodeError{URL: address, Err: errBody}
+	}
+
+	return body, nil
+}
+
+/*==================================================================================================================================*/
+
+func NewClient(config ClientConfig) (client *Client) {
+	if config.BaseURL == "" {
+		config.BaseURL = DefaultBaseURL
+	}
+	if config.Timeout == 0 {
+		config.Timeout = DefaultTimeout
+	}
+	if config.UserAgent == "" {
+		config.UserAgent = DefaultUserAgent
+	}
+	if config.RequestsPerSecond == 0 {
+		config.RequestsPerSecond = DefaultRequestsPerSecond
+	}
+	if config.Burst <= 0 {
+		config.Burst = DefaultBurst
+	}
+	if config.MaxConcurrency <= 0 {
+		config.MaxConcurrency = DefaultMaxConcurrency
+	}
+
+	httpClient := config.HTTPClient
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: config.Timeout}
+	}
+
+	client = &Client{
+		baseURL: strings.TrimRight(config.BaseURL, "/"),
+		httpClient: httpClient,
+		userAgent: config.UserAgent,
+		retry: config.Retry.withDefaults(),
+		limiter: newRateLimiter(config.RequestsPerSecond, config.Burst),
+		requestSlots: make(chan struct{}, config.MaxConcurrency),
+		maxConcurrency: config.MaxConcurrency,
+		diskCache: config.DiskCache,
+	}
+	return client
+}
@@ -0,0 +1,194 @@
+package pokeapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+	"github.com/CRowland4/pokedexcli/internal/player"
+	"github.com/CRowland4/pokedexcli/internal/pokecache"
+)
+
+const fakeAreaCount = 45
+
+// fakePokeAPI serves just enough of the PokeAPI for map, explore and catch: fakeAreaCount areas named area-1 and up,
+// each with two of the pokemon pokemon-0 to pokemon-6
+func fakePokeAPI(t *testing.T) (server *httptest.Server) {
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		parts := strings.Split(strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/v2/"), "/"), "/")
+		var body any
+		switch {
+		case len(parts) == 1 && parts[0] == "location-area":
+			body = fakeAreaList(server.URL, r)
+		case len(parts) == 2 && parts[0] == "location-area":
+			id, err := strconv.Atoi(strings.TrimPrefix(parts[1], "area-"))
+			if err != nil || id < 1 || id > fakeAreaCount {
+				http.NotFound(w, r)
+				return
+			}
+			body = fakeArea(id)
+		case len(parts) == 2 && parts[0] == "pokemon":
+			body = map[string]any{
+				"name": parts[1],
+				"species": map[string]string{"name": parts[1]},
+				"stats": []any{map[string]any{"base_stat": 45, "stat": map[string]string{"name": "hp"}}},
+				"types": []any{map[string]any{"slot": 1, "type": map[string]string{"name": "normal"}}},
+			}
+		case len(parts) == 2 && parts[0] == "pokemon-species":
+			body = map[string]any{"name": parts[1], "capture_rate": 190, "gender_rate": 4}
+		default:
+			http.NotFound(w, r)
+			return
+		}
+		json.NewEncoder(w).Encode(body)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func fakeAreaList(baseURL string, r *http.Request) (list map[string]any) {
+	offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+	pageURL := func(offset int) (address string) {
+		return fmt.Sprintf("%s/api/v2/location-area/?offset=%d&limit=%d", baseURL, offset, limit)
+	}
+
+	results := []map[string]string{}
+	for id := offset + 1; id <= min(offset + limit, fakeAreaCount); id++ {
+		results = append(results, map[string]string{"name": fmt.Sprintf("area-%d", id), "url": fmt.Sprintf("%s/api/v2/location-area/%d/", baseURL, id)})
+	}
+	list = map[string]any{"count": fakeAreaCount, "results": results, "next": nil, "previous": nil}
+	if offset + limit < fakeAreaCount {
+		list["next"] = pageURL(offset + limit)
+	}
+	if offset > 0 {
+		list["previous"] = pageURL(max(offset - limit, 0))
+	}
+	return list
+}
+
+func fakeArea(id int) (area map[string]any) {
+	encounters := []any{}
+	for _, n := range []int{id % 7, (id + 1) % 7} {
+		encounters = append(encounters, map[string]any{
+			"pokemon": map[string]string{"name": fmt.Sprintf("pokemon-%d", n)},
+			"version_details": []any{map[string]any{
+				"version": map[string]string{"name": "red"},
+				"encounter_details": []any{map[string]any{"min_level": 2, "max_level": 4, "chance": 50, "method": map[string]string{"name": "walk"}}},
+			}},
+		})
+	}
+	return map[string]any{"id": id, "name": fmt.Sprintf("area-%d", id), "pokemon_encounters": encounters}
+}
+
+// TestConcurrentMapExploreCatch pages through the map, explores areas and catches pokemon all at once, while the cache
+// keeps expiring everything and something else keeps reading it like the prompt would. Run it with -race.
+func TestConcurrentMapExploreCatch(t *testing.T) {
+	server := fakePokeAPI(t)
+	client := NewClient(ClientConfig{BaseURL: server.URL + "/api/v2", RequestsPerSecond: -1, MaxConcurrency: 4})
+	cache := pokecache.NewCache(pokecache.Config{
+		Locations: pokecache.Limits{TTL: 20 * time.Millisecond, MaxEntries: 30},
+		Pokemon: pokecache.Limits{TTL: 20 * time.Millisecond, MaxBytes: 2 << 10},
+		ReapInterval: time.Millisecond,
+	})
+	defer cache.Stop()
+	trainer := player.NewTrainer()
+	ctx := context.Background()
+
+	const workers = 4
+	const catchesPerWorker = 25
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(3)
+
+		// map to the last page and mapb back to the first, twice over
+		go func() {
+			defer wg.Done()
+			cacheLocations := client.LocationCacher()
+			for round := 0; round < 2; round++ {
+				for _, command := range []string{"map", "mapb"} {
+					for {
+						_, err := cacheLocations(ctx, &cache, command)
+						if errors.Is(err, ErrLastPage) || errors.Is(err, ErrFirstPage) {
+							break
+						} else if err != nil {
+							t.Errorf("%s: %v", command, err)
+							return
+						}
+					}
+				}
+			}
+		}()
+
+		// explore every area
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < fakeAreaCount; i++ {
+				name := fmt.Sprintf("area-%d", (w * 11 + i) % fakeAreaCount + 1)
+				if err := client.CacheLocationByName(ctx, &cache, name); err != nil {
+					t.Errorf("explore %s: %v", name, err)
+					return
+				}
+				// Another worker may have just added the area again and not its encounters yet, but never only some of them
+				if entry, ok := cache.GetLocationByName(name); ok && len(entry.Encounters) != 0 && len(entry.Encounters) != 2 {
+					t.Errorf("%s has %d encounters, want 2", name, len(entry.Encounters))
+				}
+			}
+		}(w)
+
+		// catch
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < catchesPerWorker; i++ {
+				species := fmt.Sprintf("pokemon-%d", (w + i) % 7)
+				if err := client.CacheSpecies(ctx, &cache, species); err != nil {
+					t.Errorf("catch %s: %v", species, err)
+					return
+				}
+				if _, err := trainer.Catch(player.Pokemon{Species: species, Level: 3}); err != nil {
+					t.Errorf("catch %s: %v", species, err)
+					return
+				}
+				trainer.GetParty()
+			}
+		}(w)
+	}
+
+	// What the prompt reads between commands
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 500; i++ {
+			cache.Stats()
+			cache.GetLocation(i % fakeAreaCount + 1)
+			cache.GetPokemon(fmt.Sprintf("pokemon-%d", i % 7))
+			trainer.GetCaughtPokemon()
+			trainer.GetAllPokemon()
+		}
+	}()
+
+	wg.Wait()
+	<-done
+
+	caught := trainer.GetAllPokemon()
+	if len(caught) != workers * catchesPerWorker {
+		t.Fatalf("caught %d pokemon, want %d", len(caught), workers * catchesPerWorker)
+	}
+	seen := map[int]bool{}
+	for _, pokemon := range caught {
+		if seen[pokemon.ID] || pokemon.ID < 1 || pokemon.ID > len(caught) {
+			t.Errorf("pokemon ID %d is repeated or out of range", pokemon.ID)
+		}
+		seen[pokemon.ID] = true
+	}
+	if got := len(trainer.GetCaughtPokemon()); got != 7 {
+		t.Errorf("the pokedex has %d species, want 7", got)
+	}
+}
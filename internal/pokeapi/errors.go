@@ -0,0 +1,65 @@
+package pokeapi
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// NetworkError means the request never got a response, e.g. DNS failure, refused connection or timeout
+type NetworkError struct {
+	URL string
+	Err error
+}
+
+// StatusError means the PokeAPI answered with a non-200 status code other than 404
+type StatusError struct {
+	URL string
+	StatusCode int
+	RetryAfter time.Duration
+}
+
+// DecodeError means the response body could not be read or was not the JSON we expected
+type DecodeError struct {
+	URL string
+	Err error
+}
+
+// NotFoundError means the PokeAPI has no resource at the requested URL
+type NotFoundError struct {
+	URL string
+}
+
+/*-------------------------------------------------------------------------------------------------------------------------------------------------------------------*/
+
+func (e *NetworkError) Error() (message string) {
+	cause := e.Err
+	var urlErr *url.Error
+	if errors.As(cause, &urlErr) {
+		cause = urlErr.Err
+	}
+
+	return fmt.Sprintf("could not reach the PokeAPI at %s: %v", e.URL, cause)
+}
+
+func (e *NetworkError) Unwrap() (err error) {
+	return e.Err
+}
+
+func (e *StatusError) Error() (message string) {
+	return fmt.Sprintf("the PokeAPI returned %d %s for %s", e.StatusCode, http.StatusText(e.StatusCode), e.URL)
+}
+
+func (e *DecodeError) Error() (message string) {
+	return fmt.Sprintf("could not decode the PokeAPI response from %s: %v", e.URL, e.Err)
+}
+
+func (e *DecodeError) Unwrap() (err error) {
+	return e.Err
+}
+
+func (e *NotFoundError) Error() (message string) {
+	return fmt.Sprintf("nothing found on the PokeAPI at %s", e.URL)
+}
@@ -0,0 +1,53 @@
+package pokeapi
+
+import (
+	"context"
+	"sync"
+)
+
+// flightGroup coalesces concurrent requests for the same resource URL, so that e.g. two areas on one map page
+// that both have zubat only download it once. Every caller gets the shared response body or error.
+type flightGroup struct {
+	mu sync.Mutex
+	flights map[string]*flight
+}
+
+type flight struct {
+	done chan struct{}
+	body []byte
+	err error
+}
+
+/*-------------------------------------------------------------------------------------------------------------------------------------------------------------------*/
+
+// do runs fetch for key unless a fetch for key is already in flight, in which case it waits for that one.
+// The fetch runs under the context of the caller that started it; a waiter whose own ctx is done stops waiting.
+func (g *flightGroup) do(ctx context.Context, key string, fetch func() ([]byte, error)) (body []byte, err error) {
+	g.mu.Lock()
+	if g.flights == nil {
+		g.flights = make(map[string]*flight)
+	}
+
+	if inFlight, ok := g.flights[key]; ok {
+		g.mu.Unlock()
+		select {
+		case <-inFlight.done:
+			return inFlight.body, inFlight.err
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	newFlight := &flight{done: make(chan struct{})}
+	g.flights[key] = newFlight
+	g.mu.Unlock()
+
+	newFlight.body, newFlight.err = fetch()
+	close(newFlight.done)
+
+	g.mu.Lock()
+	delete(g.flights, key)
+	g.mu.Unlock()
+
+	return newFlight.body, newFlight.err
+}
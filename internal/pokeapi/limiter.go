@@ -0,0 +1,73 @@
+package pokeapi
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// rateLimiter is a token bucket shared by every request a Client makes. It holds up to burst tokens and refills at perSecond.
+type rateLimiter struct {
+	mu sync.Mutex
+	perSecond float64
+	burst float64
+	tokens float64
+	last time.Time
+}
+
+/*-------------------------------------------------------------------------------------------------------------------------------------------------------------------*/
+
+// wait blocks until a token is available and takes it, or until ctx is done. A nil limiter never blocks.
+func (l *rateLimiter) wait(ctx context.Context) (err error) {
+	if l == nil {
+		return nil
+	}
+
+	for {
+		delay := l.reserve()
+		if delay == 0 {
+			return nil
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+func (l *rateLimiter) reserve() (delay time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	l.tokens = min(l.burst, l.tokens + now.Sub(l.last).Seconds() * l.perSecond)
+	l.last = now
+	if l.tokens >= 1 {
+		l.tokens--
+		return 0
+	}
+
+	return time.Duration((1 - l.tokens) / l.perSecond * float64(time.Second))
+}
+
+/*==================================================================================================================================*/
+
+// newRateLimiter returns nil, meaning no limit, when perSecond is not positive
+func newRateLimiter(perSecond float64, burst int) (limiter *rateLimiter) {
+	if perSecond <= 0 {
+		return nil
+	}
+
+	burst = max(burst, 1)
+	limiter = &rateLimiter{
+		perSecond: perSecond,
+		burst: float64(burst),
+		tokens: float64(burst),
+		last: time.Now(),
+	}
+	return limiter
+}
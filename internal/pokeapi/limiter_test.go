@@ -0,0 +1,83 @@
+package pokeapi
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestRateLimiterAllowsBurst(t *testing.T) {
+	limiter := newRateLimiter(1, 3)
+	for i := 0; i < 3; i++ {
+		if delay := limiter.reserve(); delay != 0 {
+			t.Fatalf("reserve %d waits %v, want the burst to go through", i + 1, delay)
+		}
+	}
+
+	if delay := limiter.reserve(); delay < 900 * time.Millisecond || delay > time.Second {
+		t.Errorf("reserve past the burst waits %v, want about a second at 1 a second", delay)
+	}
+}
+
+func TestRateLimiterPacesAfterBurst(t *testing.T) {
+	limiter := newRateLimiter(100, 3)
+
+	// Past the burst each token takes 10ms to refill
+	start := time.Now()
+	for i := 0; i < 8; i++ {
+		if err := limiter.wait(context.Background()); err != nil {
+			t.Fatalf("wait: %v", err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed < 45 * time.Millisecond {
+		t.Errorf("8 requests at 100 a second with a burst of 3 took %v, want at least 50ms", elapsed)
+	}
+}
+
+func TestRateLimiterRefillsUpToBurst(t *testing.T) {
+	limiter := newRateLimiter(1000, 2)
+	limiter.last = limiter.last.Add(-time.Hour)  // As if the client had been idle for an hour
+
+	if delay := limiter.reserve(); delay != 0 {
+		t.Fatalf("first reserve waits %v, want 0", delay)
+	}
+	if delay := limiter.reserve(); delay != 0 {
+		t.Fatalf("second reserve waits %v, want 0", delay)
+	}
+	if delay := limiter.reserve(); delay <= 0 {
+		t.Errorf("third reserve waits %v, want the idle hour to have refilled only the burst of 2", delay)
+	}
+}
+
+func TestRateLimiterWaitStopsOnCancel(t *testing.T) {
+	limiter := newRateLimiter(0.001, 1)  // One token, then one more every 1000s
+	if err := limiter.wait(context.Background()); err != nil {
+		t.Fatalf("wait: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20 * time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if err := limiter.wait(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("err = %v, want context.DeadlineExceeded", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5 * time.Second {
+		t.Errorf("wait took %v after its context ended", elapsed)
+	}
+}
+
+func TestRateLimiterDisabled(t *testing.T) {
+	for _, perSecond := range []float64{0, -1} {
+		limiter := newRateLimiter(perSecond, 5)
+		if limiter != nil {
+			t.Fatalf("newRateLimiter(%v) = %+v, want nil", perSecond, limiter)
+		}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		if err := limiter.wait(ctx); err != nil {
+			t.Errorf("a nil limiter returned %v, want it never to block or fail", err)
+		}
+	}
+}
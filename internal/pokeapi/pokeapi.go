@@ -1,16 +1,85 @@
 package pokeapi
 
 import (
-	"net/http"
-	"fmt"
-	"encoding/json"
-	"io/ioutil"
+	"context"
+	"errors"
+	"net/url"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
 	"github.com/CRowland4/pokedexcli/internal/pokecache"
-	"sync"
 )
 
 const LocationCount = 20
 
+var (
+	ErrFirstPage = errors.New("no previous locations")
+	ErrLastPage = errors.New("no more locations, this is the last page")
+	ErrNoSuchPage = errors.New("there is no page of locations with that number")
+)
+
+// One page of location areas from the map and mapb commands
+type LocationPage struct {
+	Locations []string
+	Number int
+	Total int
+}
+
+// A game version, like red or diamond
+type Version struct {
+	Name string
+}
+
+// Struct to read in a page of the paginated LocationAreas list endpoint of the PokéAPI
+type locationAreaListJSON struct {
+	Count    int     `json:"count"`
+	Next     *string `json:"next"`
+	Previous *string `json:"previous"`
+	Results  []struct {
+		Name string `json:"name"`
+		URL  string `json:"url"`
+	} `json:"results"`
+}
+
+// Struct to read in the parts of the response from the PokemonSpecies endpoint of the PokéAPI that the Pokedex uses
+type pokemonSpeciesJSON struct {
+	ID          int    `json:"id"`
+	Name        string `json:"name"`
+	CaptureRate int    `json:"capture_rate"`
+	GenderRate  int    `json:"gender_rate"`
+}
+
+// Struct to read in the parts of the response from the Items endpoint of the PokéAPI that the Pokedex uses
+type itemJSON struct {
+	ID       int    `json:"id"`
+	Name     string `json:"name"`
+	Cost     int    `json:"cost"`
+	Category struct {
+		Name string `json:"name"`
+		URL  string `json:"url"`
+	} `json:"category"`
+	EffectEntries []struct {
+		Effect      string `json:"effect"`
+		ShortEffect string `json:"short_effect"`
+		Language    struct {
+			Name string `json:"name"`
+		} `json:"language"`
+	} `json:"effect_entries"`
+	FlavorTextEntries []struct {
+		Text     string `json:"text"`
+		Language struct {
+			Name string `json:"name"`
+		} `json:"language"`
+	} `json:"flavor_text_entries"`
+}
+
+// Struct to read in the response from the Version endpoint of the PokéAPI
+type versionJSON struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
 // Struct to read in the response from the LocationAreas endpoint of the PokéAPI
 type locationAreaJSON struct {
 	ID                   int    `json:"id"`
@@ -334,78 +403,220 @@ type pokemonDataJSON struct {
 	} `json:"past_types"`
 } 
 
-func LocationCacher() (cacheLocations func(*pokecache.Cache, string) ([LocationCount]string)) {
-	currentLocationID := 1
-	
-	cacheLocations = func(cache *pokecache.Cache, command string) (locations [LocationCount]string) {
-		if command == "mapb" && currentLocationID <= LocationCount + 1 {
-			fmt.Println("No previous locations!")
-			return locations
+func (c *Client) LocationCacher() (cacheLocations func(context.Context, *pokecache.Cache, string) (LocationPage, error)) {
+	var current *locationAreaListJSON
+
+	cacheLocations = func(ctx context.Context, cache *pokecache.Cache, command string) (page LocationPage, err error) {
+		address := c.resourceURL("/location-area/?offset=0&limit=%d", LocationCount)
+		if command == "mapb" && (current == nil || current.Previous == nil) {
+			return page, ErrFirstPage
 		} else if command == "mapb" {
-			currentLocationID -= (2 * LocationCount)
+			address = *current.Previous
+		} else if current != nil && current.Next == nil {
+			return page, ErrLastPage
+		} else if current != nil {
+			address = *current.Next
+		}
+
+		page, list, err := c.cacheLocationPage(ctx, cache, address)
+
+		// Only move to the new page if some of it could be shown, so that repeating the command retries a page that failed outright
+		if len(page.Locations) > 0 {
+			current = &list
 		}
 
-		cacheAllLocationsIfNotCached(cache, currentLocationID, command)
-		locations = getCachedLocations(*cache, currentLocationID, command)
-		currentLocationID += LocationCount
-		return locations
+		return page, err
 	}
 
 	return cacheLocations
 }
 
-func getCachedLocations(cache pokecache.Cache, locationID int, command string) (locations [LocationCount]string) {
-	for i := 0; i < LocationCount; i++ {
-		entry, _ := cache.GetLocation(locationID)
-		locations[i] = entry.LocationName
-		locationID++
+// LocationPage fetches the numbered page of location areas, counting from 1, without the paging state LocationCacher keeps
+func (c *Client) LocationPage(ctx context.Context, cache *pokecache.Cache, number int) (page LocationPage, err error) {
+	if number < 1 {
+		return page, ErrNoSuchPage
 	}
-	return locations
+
+	address := c.resourceURL("/location-area/?offset=%d&limit=%d", (number - 1) * LocationCount, LocationCount)
+	page, list, err := c.cacheLocationPage(ctx, cache, address)
+	if err == nil && len(list.Results) == 0 {
+		return page, ErrNoSuchPage
+	}
+
+	return page, err
 }
 
-func cacheAllLocationsIfNotCached(cache *pokecache.Cache, currentLocationID int, command string) {
-	var wg sync.WaitGroup
-	for i := 0; i < LocationCount; i++ {
-		wg.Add(1)
-		go cacheLocationIfNotCached(cache, currentLocationID, &wg)
-		currentLocationID++
+// cacheLocationPage fetches one page of the location area list and caches every area on it. The page holds whatever
+// areas could be cached even when some of them failed.
+func (c *Client) cacheLocationPage(ctx context.Context, cache *pokecache.Cache, address string) (page LocationPage, list locationAreaListJSON, err error) {
+	list, err = c.getPokeAPILocationList(ctx, address)
+	if err != nil {
+		return page, list, err
+	}
+
+	locationIDs, err := getLocationIDs(list)
+	if err != nil {
+		return page, list, err
+	}
+
+	err = c.cacheAllLocationsIfNotCached(ctx, cache, locationIDs)
+	page = LocationPage{
+		Locations: getCachedLocations(*cache, locationIDs),
+		Number: pageNumber(address),
+		Total: (list.Count + LocationCount - 1) / LocationCount,
 	}
 
-	wg.Wait()
-	return
+	return page, list, err
 }
 
-func cacheLocationIfNotCached(cache *pokecache.Cache, locationID int, wg *sync.WaitGroup) {
-	defer wg.Done()
+func getCachedLocations(cache pokecache.Cache, locationIDs []int) (locations []string) {
+	for _, locationID := range locationIDs {
+		if entry, ok := cache.GetLocation(locationID); ok {
+			locations = append(locations, entry.LocationName)
+		}
+	}
+	return locations
+}
+
+func (c *Client) cacheAllLocationsIfNotCached(ctx context.Context, cache *pokecache.Cache, locationIDs []int) (err error) {
+	return runPool(ctx, c.maxConcurrency, len(locationIDs), func(i int) error {
+		return c.cacheLocationIfNotCached(ctx, cache, locationIDs[i])
+	})
+}
 
+func (c *Client) cacheLocationIfNotCached(ctx context.Context, cache *pokecache.Cache, locationID int) (err error) {
 	if _, ok := cache.GetLocation(locationID); ok {
-		return
+		return nil
 	}
 
-	locationResponse := getPokeAPILocation(locationID)
-	cache.AddLocation(locationID, locationResponse.Name)
+	locationResponse, err := c.getPokeAPILocation(ctx, locationID)
+	if err != nil {
+		return err
+	}
+
+	return c.cacheLocationResponse(ctx, cache, locationResponse)
+}
 
-	for _, pokemonName := range getPokemonInLocation(locationResponse) {
-		go cachePokemonInfoIfNotCached(cache, locationID, pokemonName)
-		cache.AddPokemonToLocation(locationID, pokemonName)
+// CacheLocationByName makes sure a location area is in the cache, fetching it again if it has expired since it was listed
+func (c *Client) CacheLocationByName(ctx context.Context, cache *pokecache.Cache, locationName string) (err error) {
+	if _, ok := cache.GetLocationByName(locationName); ok {
+		return nil
 	}
 
-	return
+	var locationResponse locationAreaJSON
+	err = c.getJSON(ctx, c.resourceURL("/location-area/%s/", locationName), &locationResponse)
+	if err != nil {
+		return err
+	}
+
+	return c.cacheLocationResponse(ctx, cache, locationResponse)
 }
 
-func cachePokemonInfoIfNotCached(cache *pokecache.Cache, locationID int, pokemonName string) {
-	if _, ok := cache.Pokemon[pokemonName]; ok {
-		return
+func (c *Client) cacheLocationResponse(ctx context.Context, cache *pokecache.Cache, locationResponse locationAreaJSON) (err error) {
+	locationID := locationResponse.ID
+	cache.AddLocation(locationID, locationResponse.Name)
+
+	cache.SetEncounters(locationID, getEncounters(locationResponse), getMethodRates(locationResponse))
+
+	pokemonNames := getPokemonInLocation(locationResponse)
+
+	return runPool(ctx, c.maxConcurrency, len(pokemonNames), func(i int) error {
+		return c.cachePokemonInfoIfNotCached(ctx, cache, pokemonNames[i])
+	})
+}
+
+// CachePokemon makes sure the species data for a pokemon is in the cache, e.g. for one caught in an earlier session
+func (c *Client) CachePokemon(ctx context.Context, cache *pokecache.Cache, pokemonName string) (err error) {
+	return c.cachePokemonInfoIfNotCached(ctx, cache, pokemonName)
+}
+
+func (c *Client) cachePokemonInfoIfNotCached(ctx context.Context, cache *pokecache.Cache, pokemonName string) (err error) {
+	if _, ok := cache.GetPokemon(pokemonName); ok {
+		return nil
+	}
+
+	pokemonResponse, err := c.getPokeAPIPokemon(ctx, pokemonName)
+	if err != nil {
+		return err
 	}
 
-	pokemonResponse := getPokeAPIPokemon(pokemonName)
 	cache.AddPokemon(pokemonName, extractPokemonData(pokemonResponse))
-	return
+	return nil
+}
+
+// CacheSpecies makes sure the pokemon is cached along with the capture and gender rates of its species
+func (c *Client) CacheSpecies(ctx context.Context, cache *pokecache.Cache, pokemonName string) (err error) {
+	if err = c.cachePokemonInfoIfNotCached(ctx, cache, pokemonName); err != nil {
+		return err
+	}
+
+	data, _ := cache.GetPokemon(pokemonName)
+	if data.CaptureRate > 0 {
+		return nil
+	}
+
+	species := data.Species
+	if species == "" {
+		species = pokemonName
+	}
+	speciesResponse, err := c.getPokeAPISpecies(ctx, species)
+	if err != nil {
+		return err
+	}
+
+	cache.SetSpecies(pokemonName, speciesResponse.CaptureRate, speciesResponse.GenderRate)
+	return nil
+}
+
+func (c *Client) CacheItem(ctx context.Context, cache *pokecache.Cache, itemName string) (err error) {
+	if _, ok := cache.GetItem(itemName); ok {
+		return nil
+	}
+
+	itemResponse, err := c.getPokeAPIItem(ctx, itemName)
+	if err != nil {
+		return err
+	}
+
+	cache.AddItem(itemName, extractItemData(itemResponse))
+	return nil
+}
+
+// GetVersion looks up a game version by name. A version the PokeAPI doesn't know is a NotFoundError.
+func (c *Client) GetVersion(ctx context.Context, versionName string) (version Version, err error) {
+	var versionResponse versionJSON
+	err = c.getJSON(ctx, c.resourceURL("/version/%s/", versionName), &versionResponse)
+	if err != nil {
+		return version, err
+	}
+
+	return Version{Name: versionResponse.Name}, nil
+}
+
+// extractItemData describes the item with its English short effect, or its flavor text for items that have no effect entry
+func extractItemData(data itemJSON) (extractedData pokecache.ItemData) {
+	extractedData.Category = data.Category.Name
+	extractedData.Cost = data.Cost
+
+	for _, entry := range data.EffectEntries {
+		if entry.Language.Name == "en" {
+			extractedData.Effect = entry.ShortEffect
+			return extractedData
+		}
+	}
+	for _, entry := range data.FlavorTextEntries {
+		if entry.Language.Name == "en" {
+			extractedData.Effect = strings.Join(strings.Fields(entry.Text), " ")
+			return extractedData
+		}
+	}
+
+	return extractedData
 }
 
 func extractPokemonData(data pokemonDataJSON) (extractedData pokecache.PokemonData) {
-	extractedData.IsCaught = false
 	extractedData.BaseExperience = data.BaseExperience
+	extractedData.Species = data.Species.Name
 	extractedData.Height = data.Height
 	extractedData.Weight = data.Weight
 
@@ -427,36 +638,78 @@ func extractPokemonData(data pokemonDataJSON) (extractedData pokecache.PokemonDa
 	return extractedData
 }
 
-func getPokeAPIPokemon(pokemonName string) (pokemonResponse pokemonDataJSON) {
-	address := fmt.Sprintf("https://pokeapi.co/api/v2/pokemon/%s/", pokemonName)
-	response, _ := http.Get(address)
-	defer response.Body.Close()
+func (c *Client) getPokeAPIPokemon(ctx context.Context, pokemonName string) (pokemonResponse pokemonDataJSON, err error) {
+	err = c.getJSON(ctx, c.resourceURL("/pokemon/%s/", pokemonName), &pokemonResponse)
+	return pokemonResponse, err
+}
 
-	body, _ := ioutil.ReadAll(response.Body)
-	json.Unmarshal(body, &pokemonResponse)
+func (c *Client) getPokeAPISpecies(ctx context.Context, speciesName string) (speciesResponse pokemonSpeciesJSON, err error) {
+	err = c.getJSON(ctx, c.resourceURL("/pokemon-species/%s/", speciesName), &speciesResponse)
+	return speciesResponse, err
+}
 
-	return pokemonResponse
+func (c *Client) getPokeAPIItem(ctx context.Context, itemName string) (itemResponse itemJSON, err error) {
+	err = c.getJSON(ctx, c.resourceURL("/item/%s/", itemName), &itemResponse)
+	return itemResponse, err
 }
 
-func getPokeAPILocation(id int) (locationResponse locationAreaJSON) {
-	address := fmt.Sprintf("https://pokeapi.co/api/v2/location-area/%d/", id)
-	response, errResponse := http.Get(address)
-	if errResponse != nil {
-		return
-	}
-	defer response.Body.Close()
+func (c *Client) getPokeAPILocationList(ctx context.Context, address string) (listResponse locationAreaListJSON, err error) {
+	err = c.getJSON(ctx, address, &listResponse)
+	return listResponse, err
+}
+
+func (c *Client) getPokeAPILocation(ctx context.Context, id int) (locationResponse locationAreaJSON, err error) {
+	err = c.getJSON(ctx, c.resourceURL("/location-area/%d/", id), &locationResponse)
+	return locationResponse, err
+}
 
-	body, errBody := ioutil.ReadAll(response.Body)
-	if errBody != nil {
-		return
+// getEncounters merges the encounter slots of each pokemon, version and method: their chances add up and their level ranges join.
+// Slots that depend on a condition, like a swarm, the time of day or the radio, replace the normal slots rather than
+// adding to them, so they're left out
+func getEncounters(location locationAreaJSON) (encounters []pokecache.Encounter) {
+	for _, pokemonEncounter := range location.PokemonEncounters {
+		for _, versionDetail := range pokemonEncounter.VersionDetails {
+			for _, detail := range versionDetail.EncounterDetails {
+				if len(detail.ConditionValues) > 0 {
+					continue
+				}
+				i := slices.IndexFunc(encounters, func(encounter pokecache.Encounter) bool {
+					return encounter.Pokemon == pokemonEncounter.Pokemon.Name && encounter.Version == versionDetail.Version.Name && encounter.Method == detail.Method.Name
+				})
+				if i == -1 {
+					encounters = append(encounters, pokecache.Encounter{
+						Pokemon: pokemonEncounter.Pokemon.Name,
+						Version: versionDetail.Version.Name,
+						Method: detail.Method.Name,
+						MinLevel: detail.MinLevel,
+						MaxLevel: detail.MaxLevel,
+					})
+					i = len(encounters) - 1
+				}
+
+				encounter := &encounters[i]
+				encounter.Chance = min(encounter.Chance + detail.Chance, 100)
+				encounter.MinLevel = min(encounter.MinLevel, detail.MinLevel)
+				encounter.MaxLevel = max(encounter.MaxLevel, detail.MaxLevel)
+			}
+		}
 	}
 
-	errUnmarshal := json.Unmarshal(body, &locationResponse)
-	if errUnmarshal != nil {
-		return
+	return encounters
+}
+
+func getMethodRates(location locationAreaJSON) (methodRates []pokecache.MethodRate) {
+	for _, methodRate := range location.EncounterMethodRates {
+		for _, versionDetail := range methodRate.VersionDetails {
+			methodRates = append(methodRates, pokecache.MethodRate{
+				Method: methodRate.EncounterMethod.Name,
+				Version: versionDetail.Version.Name,
+				Rate: versionDetail.Rate,
+			})
+		}
 	}
 
-	return locationResponse
+	return methodRates
 }
 
 func getPokemonInLocation(location locationAreaJSON) (pokemonNames []string) {
@@ -465,4 +718,33 @@ func getPokemonInLocation(location locationAreaJSON) (pokemonNames []string) {
 	}
 
 	return pokemonNames
+}
+
+// getLocationIDs reads the location-area IDs off the end of the URLs in a list page, e.g. .../location-area/42/
+func getLocationIDs(list locationAreaListJSON) (ids []int, err error) {
+	for _, result := range list.Results {
+		id, errID := strconv.Atoi(path.Base(strings.TrimRight(result.URL, "/")))
+		if errID != nil {
+			return nil, &DecodeError{URL: result.URL, Err: errID}
+		}
+		ids = append(ids, id)
+	}
+
+	return ids, nil
+}
+
+// pageNumber works out which page a list URL points at from its offset, counting from 1
+func pageNumber(address string) (number int) {
+	parsed, err := url.Parse(address)
+	if err != nil {
+		return 1
+	}
+
+	offset, _ := strconv.Atoi(parsed.Query().Get("offset"))
+	limit, err := strconv.Atoi(parsed.Query().Get("limit"))
+	if err != nil || limit <= 0 {
+		limit = LocationCount
+	}
+
+	return offset / limit + 1
 }
\ No newline at end of file
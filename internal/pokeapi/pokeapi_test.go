@@ -0,0 +1,48 @@
+package pokeapi
+
+import (
+	"encoding/json"
+	"slices"
+	"testing"
+	"github.com/CRowland4/pokedexcli/internal/pokecache"
+)
+
+func TestGetEncounters(t *testing.T) {
+	const area = `{"pokemon_encounters": [
+		{"pokemon": {"name": "rattata"}, "version_details": [
+			{"version": {"name": "gold"}, "encounter_details": [
+				{"min_level": 2, "max_level": 3, "chance": 30, "method": {"name": "walk"}, "condition_values": []},
+				{"min_level": 4, "max_level": 4, "chance": 20, "method": {"name": "walk"}, "condition_values": []},
+				{"min_level": 3, "max_level": 3, "chance": 40, "method": {"name": "walk"}, "condition_values": [{"name": "time-night"}]}
+			]},
+			{"version": {"name": "silver"}, "encounter_details": [
+				{"min_level": 2, "max_level": 2, "chance": 60, "method": {"name": "walk"}, "condition_values": []},
+				{"min_level": 2, "max_level": 2, "chance": 60, "method": {"name": "walk"}}
+			]}
+		]},
+		{"pokemon": {"name": "dunsparce"}, "version_details": [
+			{"version": {"name": "gold"}, "encounter_details": [
+				{"min_level": 5, "max_level": 5, "chance": 40, "method": {"name": "walk"}, "condition_values": [{"name": "swarm-yes"}]},
+				{"min_level": 10, "max_level": 10, "chance": 5, "method": {"name": "rock-smash"}, "condition_values": []}
+			]}
+		]},
+		{"pokemon": {"name": "smeargle"}, "version_details": [
+			{"version": {"name": "gold"}, "encounter_details": [
+				{"min_level": 8, "max_level": 8, "chance": 10, "method": {"name": "walk"}, "condition_values": [{"name": "radio-hoenn"}]}
+			]}
+		]}
+	]}`
+	var location locationAreaJSON
+	if err := json.Unmarshal([]byte(area), &location); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []pokecache.Encounter{
+		{Pokemon: "rattata", Version: "gold", Method: "walk", Chance: 50, MinLevel: 2, MaxLevel: 4},
+		{Pokemon: "rattata", Version: "silver", Method: "walk", Chance: 100, MinLevel: 2, MaxLevel: 2},
+		{Pokemon: "dunsparce", Version: "gold", Method: "rock-smash", Chance: 5, MinLevel: 10, MaxLevel: 10},
+	}
+	if got := getEncounters(location); !slices.Equal(got, want) {
+		t.Errorf("getEncounters() = %+v, want %+v", got, want)
+	}
+}
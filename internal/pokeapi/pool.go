@@ -0,0 +1,64 @@
+package pokeapi
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// runPool calls job(0) through job(count-1) on at most workers goroutines and returns every error they produced.
+// Jobs that have not started when ctx is done are skipped.
+func runPool(ctx context.Context, workers int, count int, job func(i int) error) (err error) {
+	var wg sync.WaitGroup
+	var errs errorList
+	indexes := make(chan int)
+
+	for w := 0; w < min(max(workers, 1), count); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				errs.add(job(i))
+			}
+		}()
+	}
+
+	for i := 0; i < count; i++ {
+		select {
+		case indexes <- i:
+			continue
+		case <-ctx.Done():
+			errs.add(ctx.Err())
+		}
+		break
+	}
+	close(indexes)
+
+	wg.Wait()
+	return errs.join()
+}
+
+/*-------------------------------------------------------------------------------------------------------------------------------------------------------------------*/
+
+// errorList gathers the errors returned by a group of fetch goroutines
+type errorList struct {
+	mu sync.Mutex
+	errs []error
+}
+
+func (l *errorList) add(err error) {
+	if err == nil {
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.errs = append(l.errs, err)
+	return
+}
+
+func (l *errorList) join() (err error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return errors.Join(l.errs...)
+}
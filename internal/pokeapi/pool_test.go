@@ -0,0 +1,95 @@
+package pokeapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRunPoolRunsEveryJobOnBoundedWorkers(t *testing.T) {
+	for _, workers := range []int{0, 1, 3, 50} {
+		t.Run(fmt.Sprint(workers), func(t *testing.T) {
+			const count = 20
+			var mu sync.Mutex
+			running, busiest := 0, 0
+			ran := map[int]int{}
+
+			err := runPool(context.Background(), workers, count, func(i int) error {
+				mu.Lock()
+				running++
+				busiest = max(busiest, running)
+				ran[i]++
+				mu.Unlock()
+
+				time.Sleep(time.Millisecond)
+				mu.Lock()
+				running--
+				mu.Unlock()
+				return nil
+			})
+			if err != nil {
+				t.Fatalf("runPool: %v", err)
+			}
+
+			for i := 0; i < count; i++ {
+				if ran[i] != 1 {
+					t.Errorf("job %d ran %d times, want once", i, ran[i])
+				}
+			}
+			if limit := min(max(workers, 1), count); busiest > limit {
+				t.Errorf("%d jobs ran at once, want at most %d", busiest, limit)
+			}
+		})
+	}
+}
+
+func TestRunPoolJoinsErrors(t *testing.T) {
+	errOdd := errors.New("odd job")
+	err := runPool(context.Background(), 4, 10, func(i int) error {
+		if i % 2 == 1 {
+			return fmt.Errorf("job %d: %w", i, errOdd)
+		}
+		return nil
+	})
+
+	if !errors.Is(err, errOdd) {
+		t.Fatalf("err = %v, want it to wrap the jobs' errors", err)
+	}
+	if joined, ok := err.(interface{ Unwrap() []error }); !ok || len(joined.Unwrap()) != 5 {
+		t.Errorf("err = %v, want the 5 errors of the odd jobs", err)
+	}
+}
+
+func TestRunPoolSkipsJobsAfterCancel(t *testing.T) {
+	const count = 100
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var ran atomic.Int64
+	err := runPool(ctx, 1, count, func(i int) error {
+		ran.Add(1)
+		cancel()
+		return nil
+	})
+
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("err = %v, want context.Canceled", err)
+	}
+	if ran.Load() >= count {
+		t.Errorf("all %d jobs ran after the context was cancelled", count)
+	}
+}
+
+func TestRunPoolWithNoJobs(t *testing.T) {
+	err := runPool(context.Background(), 4, 0, func(i int) error {
+		t.Errorf("job %d ran, want none", i)
+		return nil
+	})
+	if err != nil {
+		t.Errorf("runPool: %v", err)
+	}
+}
@@ -0,0 +1,93 @@
+package pokeapi
+
+import (
+	"errors"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RetryPolicy decides how often and how patiently a failed PokeAPI request is retried.
+// Network errors, 429s and 5xx responses are retried; 404s and decode errors are not.
+type RetryPolicy struct {
+	MaxAttempts int  // Total attempts including the first one, so 1 disables retries
+	BaseDelay time.Duration  // Delay before the first retry, doubled for every retry after that
+	MaxDelay time.Duration  // Upper bound for the backoff and for any Retry-After the server sends
+	Jitter float64  // Fraction of each delay that is randomized, between 0 and 1
+}
+
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts: 4,
+	BaseDelay: 250 * time.Millisecond,
+	MaxDelay: 5 * time.Second,
+	Jitter: 0.5,
+}
+
+/*-------------------------------------------------------------------------------------------------------------------------------------------------------------------*/
+
+func (p RetryPolicy) withDefaults() (policy RetryPolicy) {
+	policy = p
+	if policy.MaxAttempts <= 0 {
+		policy.MaxAttempts = DefaultRetryPolicy.MaxAttempts
+	}
+	if policy.BaseDelay <= 0 {
+		policy.BaseDelay = DefaultRetryPolicy.BaseDelay
+	}
+	if policy.MaxDelay <= 0 {
+		policy.MaxDelay = DefaultRetryPolicy.MaxDelay
+	}
+	policy.Jitter = min(max(policy.Jitter, 0), 1)
+	return policy
+}
+
+// delay returns how long to wait before the given retry (1 for the first retry), preferring the server's Retry-After
+func (p RetryPolicy) delay(retry int, err error) (wait time.Duration) {
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) && statusErr.RetryAfter > 0 {
+		return min(statusErr.RetryAfter, p.MaxDelay)
+	}
+
+	// Doubling stops at MaxDelay, before a shift could wrap around
+	wait = p.BaseDelay
+	for i := 1; i < retry && wait < p.MaxDelay; i++ {
+		wait *= 2
+	}
+	wait = min(wait, p.MaxDelay)
+
+	jitter := time.Duration(p.Jitter * float64(wait))
+	if jitter > 0 {
+		wait = wait - jitter + time.Duration(rand.Int63n(int64(jitter) + 1))
+	}
+
+	return wait
+}
+
+func isRetryable(err error) (retryable bool) {
+	var networkErr *NetworkError
+	var statusErr *StatusError
+	if errors.As(err, &networkErr) {
+		return true
+	} else if errors.As(err, &statusErr) {
+		return statusErr.StatusCode == http.StatusTooManyRequests || statusErr.StatusCode >= 500
+	}
+
+	return false
+}
+
+// parseRetryAfter reads a Retry-After header given either in seconds or as an HTTP date
+func parseRetryAfter(header string) (wait time.Duration) {
+	if header == "" {
+		return 0
+	}
+
+	if seconds, err := strconv.Atoi(header); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+
+	if date, err := http.ParseTime(header); err == nil {
+		return max(time.Until(date), 0)
+	}
+
+	return 0
+}
@@ -0,0 +1,296 @@
+package pokeapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// flakyServer fails the first failures requests with status, sending retryAfter if it isn't empty, then answers with body
+func flakyServer(t *testing.T, failures int, status int, retryAfter string, body string) (server *httptest.Server, hits *atomic.Int64) {
+	hits = new(atomic.Int64)
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if hits.Add(1) <= int64(failures) {
+			if retryAfter != "" {
+				w.Header().Set("Retry-After", retryAfter)
+			}
+			w.WriteHeader(status)
+			return
+		}
+		fmt.Fprint(w, body)
+	}))
+	t.Cleanup(server.Close)
+	return server, hits
+}
+
+func newTestClient(server *httptest.Server, retry RetryPolicy) (client *Client) {
+	return NewClient(ClientConfig{BaseURL: server.URL, Retry: retry, RequestsPerSecond: -1})
+}
+
+func TestGetJSONRetriesUntilSuccess(t *testing.T) {
+	server, hits := flakyServer(t, 2, http.StatusInternalServerError, "", `{"name": "pikachu"}`)
+	client := newTestClient(server, RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond, MaxDelay: 10 * time.Millisecond})
+
+	var target struct{ Name string `json:"name"` }
+	if err := client.getJSON(context.Background(), server.URL + "/pokemon/pikachu/", &target); err != nil {
+		t.Fatalf("getJSON: %v", err)
+	}
+	if target.Name != "pikachu" {
+		t.Errorf("name = %q, want pikachu", target.Name)
+	}
+	if got := hits.Load(); got != 3 {
+		t.Errorf("attempts = %d, want 3", got)
+	}
+}
+
+func TestGetJSONStopsAtMaxAttempts(t *testing.T) {
+	for _, maxAttempts := range []int{1, 2, 4} {
+		t.Run(strconv.Itoa(maxAttempts), func(t *testing.T) {
+			server, hits := flakyServer(t, 100, http.StatusServiceUnavailable, "", "{}")
+			client := newTestClient(server, RetryPolicy{MaxAttempts: maxAttempts, BaseDelay: time.Millisecond, MaxDelay: 5 * time.Millisecond})
+
+			var target struct{}
+			err := client.getJSON(context.Background(), server.URL + "/pokemon/pikachu/", &target)
+			var statusErr *StatusError
+			if !errors.As(err, &statusErr) || statusErr.StatusCode != http.StatusServiceUnavailable {
+				t.Fatalf("err = %v, want a 503 StatusError", err)
+			}
+			if got := hits.Load(); got != int64(maxAttempts) {
+				t.Errorf("attempts = %d, want %d", got, maxAttempts)
+			}
+		})
+	}
+}
+
+func TestGetJSONHonoursRetryAfter(t *testing.T) {
+	for _, status := range []int{http.StatusTooManyRequests, http.StatusServiceUnavailable} {
+		t.Run(strconv.Itoa(status), func(t *testing.T) {
+			server, hits := flakyServer(t, 1, status, "1", "{}")
+			client := newTestClient(server, RetryPolicy{MaxAttempts: 2, BaseDelay: time.Millisecond, MaxDelay: 5 * time.Second})
+
+			start := time.Now()
+			var target struct{}
+			if err := client.getJSON(context.Background(), server.URL + "/item/poke-ball/", &target); err != nil {
+				t.Fatalf("getJSON: %v", err)
+			}
+			if elapsed := time.Since(start); elapsed < 900 * time.Millisecond {
+				t.Errorf("retried after %v, want the 1s Retry-After", elapsed)
+			}
+			if got := hits.Load(); got != 2 {
+				t.Errorf("attempts = %d, want 2", got)
+			}
+		})
+	}
+}
+
+func TestGetJSONCapsRetryAfterAtMaxDelay(t *testing.T) {
+	server, _ := flakyServer(t, 1, http.StatusTooManyRequests, "120", "{}")
+	client := newTestClient(server, RetryPolicy{MaxAttempts: 2, BaseDelay: time.Millisecond, MaxDelay: 20 * time.Millisecond})
+
+	start := time.Now()
+	var target struct{}
+	if err := client.getJSON(context.Background(), server.URL + "/item/poke-ball/", &target); err != nil {
+		t.Fatalf("getJSON: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 2 * time.Second {
+		t.Errorf("retried after %v, want at most about MaxDelay", elapsed)
+	}
+}
+
+func TestGetJSONDoesNotRetryNotFound(t *testing.T) {
+	server, hits := flakyServer(t, 100, http.StatusNotFound, "", "{}")
+	client := newTestClient(server, RetryPolicy{MaxAttempts: 4, BaseDelay: time.Millisecond, MaxDelay: 5 * time.Millisecond})
+
+	var target struct{}
+	err := client.getJSON(context.Background(), server.URL + "/pokemon/missingno/", &target)
+	var notFoundErr *NotFoundError
+	if !errors.As(err, &notFoundErr) {
+		t.Fatalf("err = %v, want a NotFoundError", err)
+	}
+	if got := hits.Load(); got != 1 {
+		t.Errorf("attempts = %d, want 1", got)
+	}
+}
+
+func TestGetJSONDoesNotRetryDecodeErrors(t *testing.T) {
+	server, hits := flakyServer(t, 0, http.StatusOK, "", "not json")
+	client := newTestClient(server, RetryPolicy{MaxAttempts: 4, BaseDelay: time.Millisecond, MaxDelay: 5 * time.Millisecond})
+
+	var target struct{}
+	err := client.getJSON(context.Background(), server.URL + "/pokemon/pikachu/", &target)
+	var decodeErr *DecodeError
+	if !errors.As(err, &decodeErr) {
+		t.Fatalf("err = %v, want a DecodeError", err)
+	}
+	if got := hits.Load(); got != 1 {
+		t.Errorf("attempts = %d, want 1", got)
+	}
+}
+
+func TestGetJSONCancelStopsBackoff(t *testing.T) {
+	server, hits := flakyServer(t, 100, http.StatusServiceUnavailable, "", "{}")
+	client := newTestClient(server, RetryPolicy{MaxAttempts: 4, BaseDelay: time.Minute, MaxDelay: time.Minute})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(50 * time.Millisecond, cancel)
+
+	start := time.Now()
+	var target struct{}
+	err := client.getJSON(ctx, server.URL + "/pokemon/pikachu/", &target)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("err = %v, want context.Canceled", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5 * time.Second {
+		t.Errorf("cancelling took %v, want it to cut the backoff short", elapsed)
+	}
+	if got := hits.Load(); got != 1 {
+		t.Errorf("attempts = %d, want 1", got)
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	tests := []struct {
+		name string
+		header string
+		min time.Duration
+		max time.Duration
+	}{
+		{"empty", "", 0, 0},
+		{"seconds", "3", 3 * time.Second, 3 * time.Second},
+		{"zero seconds", "0", 0, 0},
+		{"negative seconds", "-5", 0, 0},
+		{"http date", time.Now().Add(10 * time.Second).UTC().Format(http.TimeFormat), 8 * time.Second, 10 * time.Second},
+		{"past http date", time.Now().Add(-time.Hour).UTC().Format(http.TimeFormat), 0, 0},
+		{"garbage", "soon", 0, 0},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := parseRetryAfter(test.header); got < test.min || got > test.max {
+				t.Errorf("parseRetryAfter(%q) = %v, want %v to %v", test.header, got, test.min, test.max)
+			}
+		})
+	}
+}
+
+func TestRetryPolicyDelay(t *testing.T) {
+	policy := RetryPolicy{MaxAttempts: 10, BaseDelay: 100 * time.Millisecond, MaxDelay: time.Second}
+	tests := []struct {
+		retry int
+		want time.Duration
+	}{
+		{1, 100 * time.Millisecond},
+		{2, 200 * time.Millisecond},
+		{3, 400 * time.Millisecond},
+		{4, 800 * time.Millisecond},
+		{5, time.Second},
+		{34, time.Second},
+		{63, time.Second},
+		{64, time.Second},
+		{1000, time.Second},
+	}
+
+	for _, test := range tests {
+		if got := policy.delay(test.retry, nil); got != test.want {
+			t.Errorf("delay(%d) = %v, want %v", test.retry, got, test.want)
+		}
+	}
+
+	// With a cap this high, a plain shift would wrap around to a much smaller positive delay
+	huge := RetryPolicy{MaxAttempts: 100, BaseDelay: 3 * time.Second, MaxDelay: 1 << 62}
+	for retry := 1; retry <= 100; retry++ {
+		if got, previous := huge.delay(retry + 1, nil), huge.delay(retry, nil); got < previous {
+			t.Fatalf("delay(%d) = %v, shorter than delay(%d) = %v", retry + 1, got, retry, previous)
+		}
+	}
+}
+
+func TestRetryPolicyDelayRetryAfter(t *testing.T) {
+	policy := RetryPolicy{MaxAttempts: 10, BaseDelay: 100 * time.Millisecond, MaxDelay: time.Second, Jitter: 1}
+
+	if got := policy.delay(1, &StatusError{StatusCode: 429, RetryAfter: 300 * time.Millisecond}); got != 300 * time.Millisecond {
+		t.Errorf("delay with Retry-After 300ms = %v, want 300ms", got)
+	}
+	if got := policy.delay(1, &StatusError{StatusCode: 503, RetryAfter: time.Hour}); got != time.Second {
+		t.Errorf("delay with Retry-After 1h = %v, want MaxDelay", got)
+	}
+}
+
+func TestRetryPolicyDelayJitter(t *testing.T) {
+	policy := RetryPolicy{MaxAttempts: 10, BaseDelay: 400 * time.Millisecond, MaxDelay: time.Second, Jitter: 0.5}
+	for i := 0; i < 1000; i++ {
+		if got := policy.delay(1, nil); got < 200 * time.Millisecond || got > 400 * time.Millisecond {
+			t.Fatalf("delay(1) with half jitter = %v, want 200ms to 400ms", got)
+		}
+		if got := policy.delay(3, nil); got < 500 * time.Millisecond || got > time.Second {
+			t.Fatalf("delay(3) with half jitter = %v, want 500ms to 1s", got)
+		}
+	}
+}
+
+func TestGetJSONCoalescesConcurrentRequests(t *testing.T) {
+	tests := []struct {
+		name string
+		status int
+	}{
+		{"body", http.StatusOK},
+		{"error", http.StatusNotFound},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			const callers = 20
+			var hits atomic.Int64
+			arrived := make(chan struct{})
+			release := make(chan struct{})
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if hits.Add(1) == 1 {
+					close(arrived)
+				}
+				<-release
+				w.WriteHeader(test.status)
+				fmt.Fprint(w, `{"name": "zubat"}`)
+			}))
+			defer server.Close()
+			client := newTestClient(server, RetryPolicy{MaxAttempts: 1})
+
+			var wg sync.WaitGroup
+			names := make([]string, callers)
+			errs := make([]error, callers)
+			for i := 0; i < callers; i++ {
+				wg.Add(1)
+				go func(i int) {
+					defer wg.Done()
+					var target struct{ Name string `json:"name"` }
+					errs[i] = client.getJSON(context.Background(), server.URL + "/pokemon/zubat/", &target)
+					names[i] = target.Name
+				}(i)
+			}
+
+			// Hold the one request open long enough for every caller to join it
+			<-arrived
+			time.Sleep(100 * time.Millisecond)
+			close(release)
+			wg.Wait()
+
+			if got := hits.Load(); got != 1 {
+				t.Errorf("the server got %d requests, want 1", got)
+			}
+			for i := 0; i < callers; i++ {
+				var notFoundErr *NotFoundError
+				if test.status == http.StatusOK && (errs[i] != nil || names[i] != "zubat") {
+					t.Errorf("caller %d got %q, %v, want zubat", i, names[i], errs[i])
+				} else if test.status == http.StatusNotFound && !errors.As(errs[i], &notFoundErr) {
+					t.Errorf("caller %d got %v, want the shared NotFoundError", i, errs[i])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,185 @@
+package pokecache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+/*-------------------------------------------------------------------------------------------------------------------------------------------------------------------*/
+
+const DefaultDiskEntryLifeSpan = time.Duration(30 * 24 * time.Hour)
+
+const diskEntryExtension = ".json"
+
+/*-------------------------------------------------------------------------------------------------------------------------------------------------------------------*/
+
+// DiskCache keeps raw PokeAPI responses on disk, keyed by resource URL, so they survive restarts.
+// A nil *DiskCache is a valid cache that never has anything in it.
+type DiskCache struct{
+	dir string
+	maxAge time.Duration
+	mu *sync.Mutex
+}
+
+type diskEntry struct{
+	URL string `json:"url"`
+	FetchedAt time.Time `json:"fetched_at"`
+	Body json.RawMessage `json:"body"`
+}
+
+type DiskCacheStats struct{
+	Dir string
+	Entries int
+	Bytes int64
+}
+
+/*-------------------------------------------------------------------------------------------------------------------------------------------------------------------*/
+
+func (d *DiskCache) Get(url string) (body []byte, isFound bool) {
+	if d == nil {
+		return nil, false
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	path := d.entryPath(url)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, false
+	}
+
+	var entry diskEntry
+	if err := json.Unmarshal(data, &entry); err != nil || entry.URL != url {
+		os.Remove(path)
+		return nil, false
+	}
+
+	if time.Since(entry.FetchedAt) > d.maxAge {
+		os.Remove(path)
+		return nil, false
+	}
+
+	return entry.Body, true
+}
+
+// Set stores body, which must be valid JSON, under url. The file is written to a temporary name first so a crash never leaves half an entry behind.
+func (d *DiskCache) Set(url string, body []byte) (err error) {
+	if d == nil {
+		return nil
+	}
+
+	data, err := json.Marshal(diskEntry{URL: url, FetchedAt: time.Now(), Body: body})
+	if err != nil {
+		return err
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	temp, err := os.CreateTemp(d.dir, "entry-*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(temp.Name())
+
+	if _, err = temp.Write(data); err != nil {
+		temp.Close()
+		return err
+	}
+	if err = temp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(temp.Name(), d.entryPath(url))
+}
+
+func (d *DiskCache) Stats() (stats DiskCacheStats, err error) {
+	if d == nil {
+		return stats, nil
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	stats.Dir = d.dir
+	files, err := d.entryFiles()
+	for _, file := range files {
+		if info, errInfo := os.Stat(file); errInfo == nil {
+			stats.Entries++
+			stats.Bytes += info.Size()
+		}
+	}
+
+	return stats, err
+}
+
+func (d *DiskCache) Purge() (removed int, err error) {
+	if d == nil {
+		return 0, nil
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	files, err := d.entryFiles()
+	for _, file := range files {
+		if errRemove := os.Remove(file); errRemove == nil {
+			removed++
+		} else if err == nil {
+			err = errRemove
+		}
+	}
+
+	return removed, err
+}
+
+func (d *DiskCache) entryPath(url string) (path string) {
+	sum := sha256.Sum256([]byte(url))
+	return filepath.Join(d.dir, hex.EncodeToString(sum[:]) + diskEntryExtension)
+}
+
+func (d *DiskCache) entryFiles() (files []string, err error) {
+	dirEntries, err := os.ReadDir(d.dir)
+	for _, dirEntry := range dirEntries {
+		if !dirEntry.IsDir() && strings.HasSuffix(dirEntry.Name(), diskEntryExtension) {
+			files = append(files, filepath.Join(d.dir, dirEntry.Name()))
+		}
+	}
+
+	return files, err
+}
+
+/*==================================================================================================================================*/
+
+// DefaultDiskCacheDir is the pokedexcli directory inside the user's cache directory, $XDG_CACHE_HOME or ~/.cache on Linux
+func DefaultDiskCacheDir() (dir string, err error) {
+	base, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(base, "pokedexcli", "responses"), nil
+}
+
+func NewDiskCache(dir string, maxAge time.Duration) (diskCache *DiskCache, err error) {
+	if err = os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+
+	if maxAge <= 0 {
+		maxAge = DefaultDiskEntryLifeSpan
+	}
+
+	diskCache = &DiskCache{
+		dir: dir,
+		maxAge: maxAge,
+		mu: new(sync.Mutex),
+	}
+	return diskCache, nil
+}
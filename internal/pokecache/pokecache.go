@@ -7,24 +7,62 @@ import (
 )
 /*-------------------------------------------------------------------------------------------------------------------------------------------------------------------*/
 
-const cacheEntryLifeSpan = time.Duration(1 * time.Minute)
+var DefaultConfig = Config{
+	Locations: Limits{TTL: 10 * time.Minute, MaxEntries: 500},
+	Pokemon: Limits{TTL: 30 * time.Minute, MaxBytes: 1 << 20},
+	Items: Limits{TTL: 30 * time.Minute, MaxEntries: 200},
+	ReapInterval: 1 * time.Minute,
+}
 
 /*-------------------------------------------------------------------------------------------------------------------------------------------------------------------*/
 
+// Cache holds the PokeAPI data for the session, each kind of resource with its own TTL and size limits.
+// It is safe for concurrent use, and copies of a Cache share the same entries.
 type Cache struct{
-	Info map[int]locationEntry
-	mu *sync.Mutex
-	Pokemon map[string]PokemonData
+	locations *TTLCache[int, locationEntry]
+	pokemon *TTLCache[string, PokemonData]
+	items *TTLCache[string, ItemData]
+	stop chan struct{}
+	stopOnce *sync.Once
+}
+
+type Config struct{
+	Locations Limits
+	Pokemon Limits
+	Items Limits
+	ReapInterval time.Duration
+}
+
+type CacheStats struct{
+	Locations Stats
+	Pokemon Stats
+	Items Stats
 }
 
 type locationEntry struct{
-	createdAt time.Time
 	LocationName string
-	LocationPokemon []string
+	Encounters []Encounter
+	MethodRates []MethodRate
+}
+
+// Encounter is how a pokemon can be met in an area in one game version with one method, all of its encounter slots together
+type Encounter struct{
+	Pokemon string
+	Version string
+	Method string  // walk, surf, old-rod...
+	Chance int  // Percent of this method's encounters that are this pokemon
+	MinLevel int
+	MaxLevel int
+}
+
+// MethodRate is the percent chance that using an encounter method in an area meets any pokemon at all, in one game version
+type MethodRate struct{
+	Method string
+	Version string
+	Rate int
 }
 
 type PokemonData struct{
-	IsCaught bool
 	BaseExperience int
 	Height int
 	Weight int
@@ -35,86 +73,155 @@ type PokemonData struct{
 	SpecialDefense int
 	Speed int
 	Types []string
+	Species string
+	CaptureRate int  // 0 until the species has been looked up
+	GenderRate int  // Chance of being female in eighths, -1 for genderless. Only known once CaptureRate is.
+}
+
+type ItemData struct{
+	Category string
+	Cost int
+	Effect string  // A short English description
 }
 
 /*-------------------------------------------------------------------------------------------------------------------------------------------------------------------*/
 
 func (c *Cache) AddLocation(id int, areaName string) {
-	c.mu.Lock()
-	defer c.mu.Unlock()
-	
 	newAreaEntry := locationEntry{
-		createdAt: time.Now(),
 		LocationName: areaName,
-		LocationPokemon: []string{},
 	}
 
-	c.Info[id] = newAreaEntry
+	c.locations.Set(id, newAreaEntry)
 	return
 }
 
-func (c *Cache) AddPokemonToLocation(locationID int, pokemonName string) {
-	c.mu.Lock()
-	defer c.mu.Unlock()
-	if !slices.Contains(c.Info[locationID].LocationPokemon, pokemonName) {
-		location := c.Info[locationID]
-		location.LocationPokemon = append(location.LocationPokemon, pokemonName)
-		c.Info[locationID] = location
-	}
+// SetEncounters replaces the encounter table of a cached location
+func (c *Cache) SetEncounters(locationID int, encounters []Encounter, methodRates []MethodRate) {
+	c.locations.Update(locationID, func(location locationEntry) locationEntry {
+		location.Encounters = slices.Clone(encounters)
+		location.MethodRates = slices.Clone(methodRates)
+		return location
+	})
 	return
 }
 
 func (c *Cache) AddPokemon(name string, data PokemonData) {
-	c.mu.Lock()
-	defer c.mu.Unlock()
+	c.pokemon.Set(name, data)
+	return
+}
+
+// SetSpecies adds the capture and gender rates from the pokemon's species, if the pokemon is still cached
+func (c *Cache) SetSpecies(name string, captureRate int, genderRate int) {
+	c.pokemon.Update(name, func(data PokemonData) PokemonData {
+		data.CaptureRate = captureRate
+		data.GenderRate = genderRate
+		return data
+	})
+	return
+}
 
-	c.Pokemon[name] = data
+func (c *Cache) AddItem(name string, data ItemData) {
+	c.items.Set(name, data)
 	return
 }
 
+func (c *Cache) GetItem(name string) (data ItemData, isFound bool) {
+	return c.items.Get(name)
+}
+
 func (c *Cache) GetLocation(id int) (entry locationEntry, isFound bool) {
-	c.mu.Lock()
-	defer c.mu.Unlock()
-	entry, isFound = c.Info[id]
-	if isFound {
-		return entry, true
-	}
+	entry, isFound = c.locations.Get(id)
+	return entry.clone(), isFound
+}
 
-	return entry, false
+func (c *Cache) GetLocationByName(name string) (entry locationEntry, isFound bool) {
+	_, entry, isFound = c.locations.Find(func(_ int, location locationEntry) bool {
+		return location.LocationName == name
+	})
+	return entry.clone(), isFound
 }
 
-func (c *Cache) GetCaughtPokemon() (caughtPokemon []string) {
-	for name, data := range c.Pokemon {
-		if data.IsCaught {
-			caughtPokemon = append(caughtPokemon, name)
-		}
-	}
+func (c *Cache) GetPokemon(name string) (data PokemonData, isFound bool) {
+	data, isFound = c.pokemon.Get(name)
+	data.Types = slices.Clone(data.Types)
+	return data, isFound
+}
 
-	return caughtPokemon
+func (c *Cache) Stats() (stats CacheStats) {
+	stats.Locations = c.locations.Stats()
+	stats.Pokemon = c.pokemon.Stats()
+	stats.Items = c.items.Stats()
+	return stats
 }
 
+// Stop shuts down the reaper goroutine. The cache still works afterwards, and expired entries are still dropped when they are looked up.
+func (c *Cache) Stop() {
+	c.stopOnce.Do(func() { close(c.stop) })
+	return
+}
 
 func (c *Cache) reapLoop(interval time.Duration) {
 	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
 	for {
-		currentTime := <- ticker.C
-		for id, entry := range (*c).Info {
-			entryAge := currentTime.Sub(entry.createdAt)
-			if entryAge > cacheEntryLifeSpan {
-				delete((*c).Info, id)
-			}
+		select {
+		case <-c.stop:
+			return
+		case currentTime := <- ticker.C:
+			c.locations.Reap(currentTime)
+			c.pokemon.Reap(currentTime)
+			c.items.Reap(currentTime)
 		}
 	}
 }
 
+// Returned entries get their own copies of the slices, so callers can't race with SetEncounters
+func (e locationEntry) clone() (entry locationEntry) {
+	entry = e
+	entry.Encounters = slices.Clone(e.Encounters)
+	entry.MethodRates = slices.Clone(e.MethodRates)
+	return entry
+}
+
+// Rough in-memory sizes, good enough for a byte budget
+func (e locationEntry) size() (bytes int64) {
+	bytes = int64(64 + len(e.LocationName))
+	for _, encounter := range e.Encounters {
+		bytes += int64(80 + len(encounter.Pokemon) + len(encounter.Version) + len(encounter.Method))
+	}
+	for _, rate := range e.MethodRates {
+		bytes += int64(48 + len(rate.Method) + len(rate.Version))
+	}
+	return bytes
+}
+
+func (d PokemonData) size() (bytes int64) {
+	bytes = int64(144 + len(d.Species))
+	for _, type_ := range d.Types {
+		bytes += int64(16 + len(type_))
+	}
+	return bytes
+}
+
+func (d ItemData) size() (bytes int64) {
+	return int64(64 + len(d.Category) + len(d.Effect))
+}
+
 /*==================================================================================================================================*/
 
-func NewCache(interval time.Duration) (pokeCache Cache) {
+// NewCache starts a goroutine that reaps expired entries every config.ReapInterval until Stop is called
+func NewCache(config Config) (pokeCache Cache) {
+	if config.ReapInterval <= 0 {
+		config.ReapInterval = DefaultConfig.ReapInterval
+	}
+
 	pokeCache = Cache{
-		Info: make(map[int]locationEntry),
-		mu: new(sync.Mutex),
-		Pokemon: make(map[string]PokemonData),
+		locations: NewTTLCache[int](config.Locations, locationEntry.size),
+		pokemon: NewTTLCache[string](config.Pokemon, PokemonData.size),
+		items: NewTTLCache[string](config.Items, ItemData.size),
+		stop: make(chan struct{}),
+		stopOnce: new(sync.Once),
 	}
-	go pokeCache.reapLoop(interval)
+	go pokeCache.reapLoop(config.ReapInterval)
 	return pokeCache
-}
\ No newline at end of file
+}
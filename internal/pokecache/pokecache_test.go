@@ -0,0 +1,87 @@
+package pokecache
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestCacheConcurrentUse(t *testing.T) {
+	cache := NewCache(Config{
+		Locations: Limits{TTL: 5 * time.Millisecond, MaxEntries: 20},
+		Pokemon: Limits{TTL: 5 * time.Millisecond, MaxBytes: 4 << 10},
+		Items: Limits{TTL: 5 * time.Millisecond, MaxEntries: 10},
+		ReapInterval: time.Millisecond,
+	})
+	defer cache.Stop()
+
+	var wg sync.WaitGroup
+	for worker := 0; worker < 16; worker++ {
+		wg.Add(1)
+		go func(worker int) {
+			defer wg.Done()
+			for i := 0; i < 500; i++ {
+				id := (worker * 7 + i) % 40
+				name := fmt.Sprintf("area-%d", id)
+				cache.AddLocation(id, name)
+				cache.SetEncounters(id, []Encounter{{Pokemon: "pidgey", Version: "red", Method: "walk", Chance: 50, MinLevel: 2, MaxLevel: 4}}, []MethodRate{{Method: "walk", Version: "red", Rate: 25}})
+				if entry, ok := cache.GetLocation(id); ok && len(entry.Encounters) > 0 {
+					entry.Encounters[0].Pokemon = "mutated"  // Must not reach the cached copy
+				}
+				cache.GetLocationByName(name)
+
+				pokemon := fmt.Sprintf("pokemon-%d", i % 30)
+				cache.AddPokemon(pokemon, PokemonData{Species: pokemon, Types: []string{"normal", "flying"}})
+				cache.SetSpecies(pokemon, 255, 4)
+				if data, ok := cache.GetPokemon(pokemon); ok {
+					data.Types[0] = "mutated"
+				}
+
+				cache.AddItem("poke-ball", ItemData{Category: "standard-balls", Cost: 200})
+				cache.GetItem("poke-ball")
+				cache.Stats()
+			}
+		}(worker)
+	}
+	wg.Wait()
+
+	for id := 0; id < 40; id++ {
+		if entry, ok := cache.GetLocation(id); ok {
+			for _, encounter := range entry.Encounters {
+				if encounter.Pokemon != "pidgey" {
+					t.Fatalf("location %d has encounter %q, a caller's copy leaked into the cache", id, encounter.Pokemon)
+				}
+			}
+		}
+	}
+}
+
+func TestCacheStopTwice(t *testing.T) {
+	cache := NewCache(Config{ReapInterval: time.Millisecond})
+	cache.Stop()
+	cache.Stop()
+
+	cache.AddItem("poke-ball", ItemData{Cost: 200})
+	if _, ok := cache.GetItem("poke-ball"); !ok {
+		t.Error("the cache stopped working after Stop")
+	}
+}
+
+func TestCacheReaperDropsExpiredEntries(t *testing.T) {
+	cache := NewCache(Config{Items: Limits{TTL: 5 * time.Millisecond}, ReapInterval: time.Millisecond})
+	defer cache.Stop()
+
+	cache.AddItem("poke-ball", ItemData{Cost: 200})
+	deadline := time.Now().Add(2 * time.Second)
+	for cache.Stats().Items.Entries > 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("the reaper never dropped the expired item")
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	if stats := cache.Stats().Items; stats.Expirations != 1 || stats.Hits != 0 || stats.Misses != 0 {
+		t.Errorf("stats = %+v, want one expiration and no lookups", stats)
+	}
+}
@@ -0,0 +1,198 @@
+package pokecache
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+/*-------------------------------------------------------------------------------------------------------------------------------------------------------------------*/
+
+// TTLCache is a concurrency-safe map whose entries expire after a TTL and which evicts its least recently used entries
+// once it holds more than MaxEntries entries or more than MaxBytes bytes. A zero limit means no limit.
+type TTLCache[K comparable, V any] struct{
+	mu *sync.Mutex
+	limits Limits
+	sizeOf func(V) int64
+	entries map[K]*list.Element
+	recency *list.List  // Front is the most recently used
+	bytes int64
+	stats Stats
+	now func() time.Time  // time.Now, except in tests
+}
+
+type Limits struct{
+	TTL time.Duration
+	MaxEntries int
+	MaxBytes int64
+}
+
+type Stats struct{
+	Entries int
+	Bytes int64
+	Hits uint64
+	Misses uint64
+	Evictions uint64  // Pushed out by MaxEntries or MaxBytes
+	Expirations uint64  // Outlived the TTL
+}
+
+type ttlEntry[K comparable, V any] struct{
+	key K
+	value V
+	createdAt time.Time
+	size int64
+}
+
+/*-------------------------------------------------------------------------------------------------------------------------------------------------------------------*/
+
+func (c *TTLCache[K, V]) Get(key K) (value V, isFound bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	element, ok := c.live(key, c.now())
+	if !ok {
+		c.stats.Misses++
+		return value, false
+	}
+
+	c.stats.Hits++
+	c.recency.MoveToFront(element)
+	return element.Value.(*ttlEntry[K, V]).value, true
+}
+
+// Find returns the first live entry that matches, in no particular order
+func (c *TTLCache[K, V]) Find(match func(K, V) bool) (key K, value V, isFound bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.now()
+	for element := c.recency.Front(); element != nil; element = element.Next() {
+		entry := element.Value.(*ttlEntry[K, V])
+		if !c.isExpired(entry, now) && match(entry.key, entry.value) {
+			c.stats.Hits++
+			c.recency.MoveToFront(element)
+			return entry.key, entry.value, true
+		}
+	}
+
+	c.stats.Misses++
+	return key, value, false
+}
+
+func (c *TTLCache[K, V]) Set(key K, value V) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if element, ok := c.entries[key]; ok {
+		c.remove(element)
+	}
+
+	entry := &ttlEntry[K, V]{key: key, value: value, createdAt: c.now(), size: c.sizeOf(value)}
+	c.entries[key] = c.recency.PushFront(entry)
+	c.bytes += entry.size
+	c.evictOverflow()
+	return
+}
+
+// Update replaces a live entry with change(entry) without resetting its age. It does nothing if key isn't cached.
+func (c *TTLCache[K, V]) Update(key K, change func(V) V) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	element, ok := c.live(key, c.now())
+	if !ok {
+		return
+	}
+
+	entry := element.Value.(*ttlEntry[K, V])
+	entry.value = change(entry.value)
+	c.bytes -= entry.size
+	entry.size = c.sizeOf(entry.value)
+	c.bytes += entry.size
+	c.recency.MoveToFront(element)
+	c.evictOverflow()
+	return
+}
+
+// Reap drops every entry that has outlived the TTL
+func (c *TTLCache[K, V]) Reap(now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for element := c.recency.Back(); element != nil; {
+		previous := element.Prev()
+		if c.isExpired(element.Value.(*ttlEntry[K, V]), now) {
+			c.remove(element)
+			c.stats.Expirations++
+		}
+		element = previous
+	}
+	return
+}
+
+func (c *TTLCache[K, V]) Stats() (stats Stats) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	stats = c.stats
+	stats.Entries = len(c.entries)
+	stats.Bytes = c.bytes
+	return stats
+}
+
+// live finds key, dropping it instead if it has expired
+func (c *TTLCache[K, V]) live(key K, now time.Time) (element *list.Element, isFound bool) {
+	element, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+
+	if c.isExpired(element.Value.(*ttlEntry[K, V]), now) {
+		c.remove(element)
+		c.stats.Expirations++
+		return nil, false
+	}
+
+	return element, true
+}
+
+func (c *TTLCache[K, V]) isExpired(entry *ttlEntry[K, V], now time.Time) (isExpired bool) {
+	return c.limits.TTL > 0 && now.Sub(entry.createdAt) > c.limits.TTL
+}
+
+func (c *TTLCache[K, V]) evictOverflow() {
+	for c.recency.Len() > 1 && c.isOverLimit() {
+		c.remove(c.recency.Back())
+		c.stats.Evictions++
+	}
+	return
+}
+
+func (c *TTLCache[K, V]) isOverLimit() (isOver bool) {
+	return (c.limits.MaxEntries > 0 && len(c.entries) > c.limits.MaxEntries) || (c.limits.MaxBytes > 0 && c.bytes > c.limits.MaxBytes)
+}
+
+func (c *TTLCache[K, V]) remove(element *list.Element) {
+	entry := c.recency.Remove(element).(*ttlEntry[K, V])
+	delete(c.entries, entry.key)
+	c.bytes -= entry.size
+	return
+}
+
+/*==================================================================================================================================*/
+
+// NewTTLCache needs sizeOf only when limits.MaxBytes is set; it should return a rough size of a value in bytes
+func NewTTLCache[K comparable, V any](limits Limits, sizeOf func(V) int64) (cache *TTLCache[K, V]) {
+	if sizeOf == nil {
+		sizeOf = func(V) int64 { return 0 }
+	}
+
+	cache = &TTLCache[K, V]{
+		mu: new(sync.Mutex),
+		limits: limits,
+		sizeOf: sizeOf,
+		entries: make(map[K]*list.Element),
+		recency: list.New(),
+		now: time.Now,
+	}
+	return cache
+}
@@ -0,0 +1,173 @@
+package pokecache
+
+import (
+	"testing"
+	"time"
+)
+
+// fakeClock stands in for time.Now so that entries age exactly as much as a test says
+type fakeClock struct {
+	current time.Time
+}
+
+func (c *fakeClock) now() (now time.Time) {
+	return c.current
+}
+
+func (c *fakeClock) advance(d time.Duration) {
+	c.current = c.current.Add(d)
+	return
+}
+
+func newTestTTLCache[V any](limits Limits, sizeOf func(V) int64) (cache *TTLCache[string, V], clock *fakeClock) {
+	clock = &fakeClock{current: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	cache = NewTTLCache[string](limits, sizeOf)
+	cache.now = clock.now
+	return cache, clock
+}
+
+func TestTTLCacheExpiresOnLookup(t *testing.T) {
+	cache, clock := newTestTTLCache[int](Limits{TTL: 10 * time.Second}, nil)
+	cache.Set("a", 1)
+
+	clock.advance(10 * time.Second)
+	if value, ok := cache.Get("a"); !ok || value != 1 {
+		t.Fatalf("Get(a) at exactly the TTL = %d, %v, want 1, true", value, ok)
+	}
+
+	clock.advance(time.Nanosecond)
+	if _, ok := cache.Get("a"); ok {
+		t.Error("Get(a) found an entry older than the TTL")
+	}
+
+	stats := cache.Stats()
+	if stats.Entries != 0 || stats.Hits != 1 || stats.Misses != 1 || stats.Expirations != 1 || stats.Evictions != 0 {
+		t.Errorf("stats = %+v, want no entries, 1 hit, 1 miss and 1 expiration", stats)
+	}
+}
+
+func TestTTLCacheWithoutTTLNeverExpires(t *testing.T) {
+	cache, clock := newTestTTLCache[int](Limits{}, nil)
+	cache.Set("a", 1)
+	clock.advance(1000 * time.Hour)
+	cache.Reap(clock.now())
+
+	if _, ok := cache.Get("a"); !ok {
+		t.Error("an entry expired with no TTL set")
+	}
+}
+
+func TestTTLCacheSetResetsAge(t *testing.T) {
+	cache, clock := newTestTTLCache[int](Limits{TTL: 10 * time.Second}, nil)
+	cache.Set("a", 1)
+	clock.advance(8 * time.Second)
+	cache.Set("a", 2)
+	clock.advance(8 * time.Second)
+
+	if value, ok := cache.Get("a"); !ok || value != 2 {
+		t.Errorf("Get(a) = %d, %v, want 2, true", value, ok)
+	}
+}
+
+func TestTTLCacheUpdateKeepsAge(t *testing.T) {
+	cache, clock := newTestTTLCache[int](Limits{TTL: 30 * time.Second}, nil)
+	cache.Set("a", 1)
+	clock.advance(20 * time.Second)
+	cache.Update("a", func(value int) int { return value + 1 })
+	cache.Update("missing", func(value int) int { return value + 1 })
+
+	if value, ok := cache.Get("a"); !ok || value != 2 {
+		t.Fatalf("Get(a) = %d, %v, want 2, true", value, ok)
+	}
+	if _, ok := cache.Get("missing"); ok {
+		t.Error("Update added a key that wasn't cached")
+	}
+
+	clock.advance(11 * time.Second)
+	if _, ok := cache.Get("a"); ok {
+		t.Error("Update reset the entry's age")
+	}
+}
+
+func TestTTLCacheReapDropsOnlyExpired(t *testing.T) {
+	cache, clock := newTestTTLCache[int](Limits{TTL: 10 * time.Second}, nil)
+	cache.Set("old", 1)
+	clock.advance(5 * time.Second)
+	cache.Set("new", 2)
+	clock.advance(6 * time.Second)
+	cache.Reap(clock.now())
+
+	stats := cache.Stats()
+	if stats.Entries != 1 || stats.Expirations != 1 || stats.Hits != 0 || stats.Misses != 0 {
+		t.Errorf("stats = %+v, want 1 entry left, 1 expiration and no lookups", stats)
+	}
+	if _, ok := cache.Get("new"); !ok {
+		t.Error("Reap dropped an entry that hadn't expired")
+	}
+}
+
+func TestTTLCacheFindSkipsExpired(t *testing.T) {
+	cache, clock := newTestTTLCache[int](Limits{TTL: 10 * time.Second}, nil)
+	cache.Set("old", 7)
+	clock.advance(11 * time.Second)
+	cache.Set("new", 7)
+
+	key, _, ok := cache.Find(func(_ string, value int) bool { return value == 7 })
+	if !ok || key != "new" {
+		t.Errorf("Find = %q, %v, want new, true", key, ok)
+	}
+	if _, _, ok = cache.Find(func(_ string, value int) bool { return value == 8 }); ok {
+		t.Error("Find matched nothing but reported a hit")
+	}
+
+	if stats := cache.Stats(); stats.Hits != 1 || stats.Misses != 1 {
+		t.Errorf("stats = %+v, want 1 hit and 1 miss", stats)
+	}
+}
+
+func TestTTLCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	cache, _ := newTestTTLCache[int](Limits{MaxEntries: 2}, nil)
+	cache.Set("a", 1)
+	cache.Set("b", 2)
+	cache.Get("a")
+	cache.Set("c", 3)
+
+	if _, ok := cache.Get("b"); ok {
+		t.Error("b is still cached, but it was the least recently used")
+	}
+	for _, key := range []string{"a", "c"} {
+		if _, ok := cache.Get(key); !ok {
+			t.Errorf("%s was evicted", key)
+		}
+	}
+
+	if stats := cache.Stats(); stats.Entries != 2 || stats.Evictions != 1 || stats.Expirations != 0 {
+		t.Errorf("stats = %+v, want 2 entries and 1 eviction", stats)
+	}
+}
+
+func TestTTLCacheEvictsOverMaxBytes(t *testing.T) {
+	cache, _ := newTestTTLCache[string](Limits{MaxBytes: 10}, func(value string) int64 {
+		return int64(len(value))
+	})
+	cache.Set("a", "1234")
+	cache.Set("b", "1234")
+	cache.Set("c", "1234")
+
+	if _, ok := cache.Get("a"); ok {
+		t.Error("a is still cached past MaxBytes")
+	}
+	if stats := cache.Stats(); stats.Entries != 2 || stats.Bytes != 8 || stats.Evictions != 1 {
+		t.Errorf("stats = %+v, want 2 entries of 8 bytes and 1 eviction", stats)
+	}
+
+	cache.Update("b", func(string) string { return "1234567" })  // Growing b pushes out c, the least recently used
+	if _, ok := cache.Get("c"); ok {
+		t.Error("c is still cached after b grew past MaxBytes")
+	}
+
+	cache.Set("huge", "12345678901234567890")  // Too big on its own, but the newest entry is always kept
+	if stats := cache.Stats(); stats.Entries != 1 || stats.Bytes != 20 || stats.Evictions != 3 {
+		t.Errorf("stats = %+v, want only the huge entry left after 3 evictions", stats)
+	}
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"sync"
+)
+
+// interruptHandler turns Ctrl-C into cancelling the command that is running, instead of killing the Pokedex and the session with it.
+// Between commands Ctrl-C only ends the Pokedex when there's no prompt to type exit at, as when reading commands from a pipe.
+type interruptHandler struct {
+	mu sync.Mutex
+	cancel context.CancelFunc
+	isInteractive bool  // Whether a Ctrl-C between commands redraws the prompt, rather than exiting
+}
+
+// interruptedExitCode is what shells use for a program ended by SIGINT
+const interruptedExitCode = 130
+
+/*-------------------------------------------------------------------------------------------------------------------------------------------------------------------*/
+
+// commandContext returns a context that is cancelled on Ctrl-C until done is called
+func (h *interruptHandler) commandContext() (ctx context.Context, done func()) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	h.mu.Lock()
+	h.cancel = cancel
+	h.mu.Unlock()
+
+	done = func() {
+		h.mu.Lock()
+		h.cancel = nil
+		h.mu.Unlock()
+		cancel()
+	}
+	return ctx, done
+}
+
+// setInteractive makes a Ctrl-C between commands remind the user how to quit instead of ending the Pokedex. The reminder
+// goes to stderr so it never mixes with output.
+func (h *interruptHandler) setInteractive() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.isInteractive = true
+	return
+}
+
+func (h *interruptHandler) handle(signals chan os.Signal) {
+	for range signals {
+		h.mu.Lock()
+		if h.cancel != nil {
+			h.cancel()
+		} else if h.isInteractive {
+			fmt.Fprint(os.Stderr, "\n(type exit to quit)\nPokedex > ")
+		} else {
+			os.Exit(interruptedExitCode)
+		}
+		h.mu.Unlock()
+	}
+}
+
+/*==================================================================================================================================*/
+
+func newInterruptHandler() (handler *interruptHandler) {
+	handler = &interruptHandler{}
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt)
+	go handler.handle(signals)
+	return handler
+}
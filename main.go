@@ -1,223 +1,1041 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"fmt"
-	"bufio"
+	"flag"
 	"os"
+	"path/filepath"
+	"strconv"
 	"time"
-	"math/rand"
 	"slices"
 	"strings"
 	"github.com/CRowland4/pokedexcli/internal/pokeapi"
 	"github.com/CRowland4/pokedexcli/internal/pokecache"
+	"github.com/CRowland4/pokedexcli/internal/player"
+	"github.com/CRowland4/pokedexcli/internal/lineedit"
+	"github.com/CRowland4/pokedexcli/internal/game"
 )
 const (
 	lineSeparator = "\n\n+=+=+=+=+=+=+=+=+=+=+=+=+=+=+=+=+=+=+=+=+=+=+=+=+=+=+=+=+=+=+=+=+=+=+=+=+=+=+=+=+=+=+=+=+=+=+=+=+=+=+=+=+=+=+=+=+=+=+=+=+\n\n"
-	helpMessage = `Usage:
-	help: Display all commands
-	map: Display next 20 locations
-	mapb: Display previous 20 locations
-	explore <area>: Discover the pokemon located in one of your current locations
-	catch <pokemon>: Attempt to catch one of the pokemon you have discovered form exploring an area
-	inspect <pokemon>: Inspect a pokemon that you have caught
-	pokedex: View the names of all the pokemon that you have caught
-	exit: Exit the Pokedex`
 	welcomMessage = "Welcome to the Pokedex!\n\nUsage:\nhelp: Display all commands\nexit: Exit the Pokedex"
 )
 
 func main() {
-	fmt.Print(welcomMessage)
-
-	cache := pokecache.NewCache(5 * time.Minute)
-	locationCacher := pokeapi.LocationCacher()
-
-	var currentLocations [pokeapi.LocationCount]string
-	var currentPokemon []string
-
-	for {
-		command := getCommand()
-		if command == "exit" {
-			return
-		} else if command == "help" {
-			fmt.Print(helpMessage)
-		} else if command == "map" || command == "mapb" {
-			currentLocations = locationCacher(&cache, command)
-			currentPokemon = []string{}
-			printLocations(currentLocations)
-		} else if strings.Contains(command, "explore") {
-			currentPokemon = getAreaPokemon(command, currentLocations, cache)
-			printAreaPokemon(currentPokemon)
-		} else if strings.Contains(command, "catch") {
-			catchPokemon(cache, command, currentPokemon)
-		} else if strings.Contains(command, "inspect") {
-			inspectPokemon(cache, command)
-		} else if command == "pokedex" {
-			printCaughtPokemon(cache)
-		} else {
-			fmt.Print("Command not recognized")
+	apiURL := flag.String("api-url", envOrDefault(pokeapi.BaseURLEnvVar, pokeapi.DefaultBaseURL), "base URL of the PokeAPI to use (env "+pokeapi.BaseURLEnvVar+")")
+	timeout := flag.Duration("timeout", pokeapi.DefaultTimeout, "timeout for each PokeAPI request")
+	userAgent := flag.String("user-agent", pokeapi.DefaultUserAgent, "User-Agent header sent to the PokeAPI")
+	retries := flag.Int("retries", pokeapi.DefaultRetryPolicy.MaxAttempts, "attempts per PokeAPI request before giving up (1 disables retries)")
+	retryDelay := flag.Duration("retry-delay", pokeapi.DefaultRetryPolicy.BaseDelay, "backoff before the first retry, doubled on each further retry")
+	rate := flag.Float64("rate", pokeapi.DefaultRequestsPerSecond, "maximum PokeAPI requests per second (negative for no limit)")
+	burst := flag.Int("burst", pokeapi.DefaultBurst, "number of PokeAPI requests allowed in a burst above the rate")
+	concurrency := flag.Int("concurrency", pokeapi.DefaultMaxConcurrency, "maximum simultaneous PokeAPI requests")
+	defaultCacheDir, _ := pokecache.DefaultDiskCacheDir()
+	cacheDir := flag.String("cache-dir", defaultCacheDir, "directory for PokeAPI responses cached on disk")
+	cacheMaxAge := flag.Duration("cache-max-age", pokecache.DefaultDiskEntryLifeSpan, "how long a PokeAPI response cached on disk stays valid")
+	noDiskCache := flag.Bool("no-disk-cache", false, "don't read or write the on-disk PokeAPI cache")
+	locationTTL := flag.Duration("location-ttl", pokecache.DefaultConfig.Locations.TTL, "how long a location area stays in the in-memory cache")
+	pokemonTTL := flag.Duration("pokemon-ttl", pokecache.DefaultConfig.Pokemon.TTL, "how long a pokemon stays in the in-memory cache")
+	maxLocations := flag.Int("max-cached-locations", pokecache.DefaultConfig.Locations.MaxEntries, "most location areas kept in memory before the least recently used are evicted (0 for no limit)")
+	maxPokemonBytes := flag.Int64("max-cached-pokemon-bytes", pokecache.DefaultConfig.Pokemon.MaxBytes, "memory budget for cached pokemon before the least recently used are evicted (0 for no limit)")
+	defaultSavePath, _ := player.DefaultSavePath()
+	savePath := flag.String("save", defaultSavePath, "save file for the pokemon you catch")
+	historyPath := flag.String("history", filepath.Join(filepath.Dir(defaultSavePath), "history"), "file for the command history (empty to keep none)")
+	gameVersion := flag.String("version", "", "game version to play, like red or diamond: only its areas and encounters are shown (remembered in the save file, \"all\" for every version)")
+	seed := flag.Int64("seed", 0, "seed for every random roll, to replay a session exactly (0 picks one)")
+	transcriptPath := flag.String("transcript", "", "file to append every command to, starting with the seed, so it can be replayed as a script")
+	output := flag.String("output", string(formatTable), "how to show results: "+outputFormatNames())
+	batchCommands := flag.String("c", "", "commands to run instead of the prompt, separated by semicolons")
+	subcommands := newCommandRegistry()
+	registerSubcommands(subcommands)
+	commands := newCommandRegistry()
+	registerCommands(commands)
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [flags] [script]\n       %s [flags] <command> [args]\n\n", os.Args[0], os.Args[0])
+		fmt.Fprint(flag.CommandLine.Output(), "With -c or a script file the commands run in order, stopping at the first that fails.\n")
+		fmt.Fprint(flag.CommandLine.Output(), "With a command the result is shown once, without starting the prompt.\n\nCommands:\n")
+		for _, c := range subcommands.help().Commands {
+			fmt.Fprintf(flag.CommandLine.Output(), "  %s %s\n    \t%s\n", c.Name, c.Args, c.Description)
 		}
+		fmt.Fprint(flag.CommandLine.Output(), "\nFlags:\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	_, isSubcommand := subcommands.find(flag.Arg(0))
+	if !isSubcommand && (flag.NArg() > 1 || (flag.NArg() == 1 && *batchCommands != "")) {
+		flag.Usage()
+		os.Exit(2)
+	} else if !isSubcommand && flag.NArg() == 1 && isPromptCommand(commands, flag.Arg(0)) {
+		names := []string{}
+		for _, c := range subcommands.help().Commands {
+			names = append(names, c.Name)
+		}
+		fmt.Fprintf(os.Stderr, "%s only works at the prompt or in a script, try -c %q\nThe commands that run on their own are: %s\n", flag.Arg(0), flag.Arg(0), strings.Join(names, ", "))
+		os.Exit(2)
+	}
+	format, err := parseOutputFormat(*output)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+
+	trainer, err := player.Load(*savePath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Couldn't load your save file: %v\nMove it out of the way or choose another file with -save\n", err)
+		os.Exit(1)
+	}
+
+	var diskCache *pokecache.DiskCache
+	if !*noDiskCache && *cacheDir != "" {
+		if diskCache, err = pokecache.NewDiskCache(*cacheDir, *cacheMaxAge); err != nil {
+			fmt.Fprintln(os.Stderr, "Can't use the on-disk cache, everything will be downloaded fresh:", err)
+		}
+	}
+
+	client := pokeapi.NewClient(pokeapi.ClientConfig{
+		BaseURL: *apiURL,
+		Timeout: *timeout,
+		UserAgent: *userAgent,
+		Retry: pokeapi.RetryPolicy{
+			MaxAttempts: *retries,
+			BaseDelay: *retryDelay,
+			MaxDelay: pokeapi.DefaultRetryPolicy.MaxDelay,
+			Jitter: pokeapi.DefaultRetryPolicy.Jitter,
+		},
+		RequestsPerSecond: *rate,
+		Burst: *burst,
+		MaxConcurrency: *concurrency,
+		DiskCache: diskCache,
+	})
+	cache := pokecache.NewCache(pokecache.Config{
+		Locations: pokecache.Limits{TTL: *locationTTL, MaxEntries: *maxLocations},
+		Pokemon: pokecache.Limits{TTL: *pokemonTTL, MaxBytes: *maxPokemonBytes},
+		Items: pokecache.DefaultConfig.Items,
+		ReapInterval: pokecache.DefaultConfig.ReapInterval,
+	})
+
+	s := &session{
+		client: client,
+		cache: cache,
+		diskCache: diskCache,
+		locationCacher: client.LocationCacher(),
+		trainer: trainer,
+		savePath: *savePath,
+		commands: commands,
+	}
+	s.setFormat(format)
+	if *seed == 0 {
+		*seed = newSeed()
+	}
+	s.setSeed(*seed)
+	if savedVersion := trainer.GameVersion(); *gameVersion != "" {
+		if err = s.setGameVersion(context.Background(), *gameVersion); err != nil {
+			printError(err)
+			os.Exit(2)
+		} else if trainer.GameVersion() != savedVersion {
+			s.autosave()
+		}
+	}
+	interrupts := newInterruptHandler()
+
+	if *transcriptPath != "" && !isSubcommand {
+		if err = s.startTranscript(*transcriptPath); err != nil {
+			fmt.Fprintln(os.Stderr, "Couldn't start the transcript:", err)
+			os.Exit(1)
+		}
+	}
+
+	var exitCode int
+	if isSubcommand {
+		exitCode = runSubcommand(s, interrupts, subcommands, flag.Args())
+	} else if *batchCommands != "" || flag.NArg() == 1 {
+		exitCode = runBatch(s, interrupts, *batchCommands, flag.Arg(0))
+	} else {
+		exitCode = runPrompt(s, interrupts, *historyPath)
+	}
+
+	cache.Stop()
+	if s.transcript != nil {
+		s.transcript.Close()
+	}
+	os.Exit(exitCode)
+}
+
+// runPrompt reads commands from stdin until exit or the end of input. When stdin isn't a terminal there's no prompt,
+// and the first command that fails ends the session with a non-zero exit code, just like a script.
+func runPrompt(s *session, interrupts *interruptHandler, historyPath string) (exitCode int) {
+	editor := lineedit.New(os.Stdin, os.Stdout)
+	isInteractive := editor.IsTerminal()
+	if isInteractive {
+		interrupts.setInteractive()
+		fmt.Print(welcomMessage)
+		editor.Complete = s.complete
+		if historyPath != "" {
+			if err := editor.LoadHistory(historyPath); err != nil {
+				fmt.Print("\n\nCan't read your command history: ", err)
+			}
+		}
+	}
+
+	for !s.isExiting {
+		line, err := getCommand(editor, isInteractive)
+		if errors.Is(err, lineedit.ErrInterrupted) {
+			fmt.Print("(type exit to quit)")
+			continue
+		} else if err != nil {
+			line = "exit"
+		} else if isInteractive {
+			editor.AddHistory(line)
+		}
+		isEndOfInput := err != nil
+
+		ctx, done := interrupts.commandContext()
+		err = s.commands.run(ctx, s, line)
+		done()
+		if !isEndOfInput {
+			s.record(line, err)
+		}
+		printError(err)
+		if err != nil && !isInteractive {
+			return 1
+		}
+	}
+
+	return 0
+}
+
+// runBatch runs the commands given with -c, or else the script file, and reports whether they all succeeded
+func runBatch(s *session, interrupts *interruptHandler, commands string, scriptPath string) (exitCode int) {
+	script := commands
+	if script == "" {
+		var err error
+		if script, err = readScript(scriptPath); err != nil {
+			printError(err)
+			return 1
+		}
+	}
+
+	ctx, done := interrupts.commandContext()
+	err := s.runScript(ctx, script, true)
+	done()
+	if err != nil {
+		printError(err)
+		return 1
 	}
 
+	return 0
+}
+
+func registerCommands(r *commandRegistry) {
+	r.register(command{name: "help", aliases: []string{"?"}, description: "Display all commands", maxArgs: 0, handler: commandHelp})
+	r.register(command{name: "map", description: "Display next 20 locations", maxArgs: 0, handler: commandMap})
+	r.register(command{name: "mapb", description: "Display previous 20 locations", maxArgs: 0, handler: commandMapBack})
+	r.register(command{name: "explore", args: "<area>", description: "Discover the pokemon located in one of your current locations", minArgs: 1, maxArgs: 1, handler: commandExplore, complete: completeArea})
+	r.register(command{name: "walk", description: "Walk through the tall grass of the area you're exploring until a wild pokemon appears", maxArgs: 0, handler: commandWalk})
+	r.register(command{name: "fish", args: "<old|good|super>", description: "Fish with a rod in the area you're exploring, hoping for a bite", minArgs: 1, maxArgs: 1, handler: commandFish, complete: completeRod})
+	r.register(command{name: "encounter", args: "[method]", description: "Look for a wild pokemon in the area you're exploring with any encounter method it has, like surf or headbutt, walking unless you say otherwise", maxArgs: 1, handler: commandEncounter, complete: completeMethod})
+	r.register(command{name: "catch", args: "<pokemon> [--ball <ball>] [--hp <percent>] [--status <status>] [--odds]", description: "Throw a ball from your bag, a Poke Ball unless you pick another, at the wild pokemon you have encountered. It's easier when the pokemon is weakened or has a status, and --odds shows the chance of it working", minArgs: 1, maxArgs: -1, handler: commandCatch, complete: completeWild})
+	r.register(command{name: "inspect", args: "<pokemon>", description: "Inspect one of the pokemon you have caught, picked by its ID, nickname or species: its level, nature, IVs and stats", minArgs: 1, maxArgs: 1, handler: commandInspect, complete: completeCaught})
+	r.register(command{name: "pokedex", aliases: []string{"dex"}, description: "View every species you have caught and how many of each you have", maxArgs: 0, handler: commandPokedex})
+	r.register(command{name: "party", description: "List the pokemon in your party", maxArgs: 0, handler: commandParty})
+	r.register(command{name: "box", args: "[n]", description: "List the pokemon in one of your PC boxes, box 1 unless you pick another", maxArgs: 1, handler: commandBox})
+	r.register(command{name: "deposit", args: "<pokemon> [box]", description: "Move a pokemon from your party into a PC box, the first with room unless you pick one", minArgs: 1, maxArgs: 2, handler: commandDeposit, complete: completeParty})
+	r.register(command{name: "withdraw", args: "<pokemon>", description: "Move a pokemon from its PC box into your party", minArgs: 1, maxArgs: 1, handler: commandWithdraw, complete: completeCaught})
+	r.register(command{name: "swap", args: "<pokemon> <pokemon>", description: "Swap the places of two pokemon, to reorder your party or trade a party member for a boxed one", minArgs: 2, maxArgs: 2, handler: commandSwap, complete: completeSwap})
+	r.register(command{name: "release", args: "<pokemon>", description: "Let one of your pokemon go for good. Its species stays in your pokedex.", minArgs: 1, maxArgs: 1, handler: commandRelease, complete: completeCaught})
+	r.register(command{name: "bag", description: "List the items in your bag", maxArgs: 0, handler: commandBag})
+	r.register(command{name: "nickname", args: "<pokemon> [nickname]", description: "Give one of your pokemon, picked by its ID, nickname or species, a nickname, or clear it", minArgs: 1, maxArgs: -1, handler: commandNickname, complete: completeCaught})
+	r.register(command{name: "save", args: "[file]", description: "Save your Pokedex, to another save file if one is given", maxArgs: 1, handler: commandSave})
+	r.register(command{name: "load", args: "<file>", description: "Load the Pokedex from a save file and keep saving to it", minArgs: 1, maxArgs: 1, handler: commandLoad})
+	r.register(command{name: "cache", args: "[purge]", description: "Show how the in-memory and on-disk caches are doing, or delete every PokeAPI response cached on disk", maxArgs: 1, handler: commandCache, complete: completeCache})
+	r.register(command{name: "format", args: "[table|json|yaml]", description: "Show or change how results are shown: as text tables, one JSON object per line, or YAML documents", maxArgs: 1, handler: commandFormat, complete: completeFormat})
+	r.register(command{name: "version", args: "[version|all]", description: "Show or change the game version you're playing, like red or diamond, which limits maps, areas and encounters to that game", maxArgs: 1, handler: commandVersion, complete: completeVersion})
+	r.register(command{name: "seed", args: "[seed]", description: "Show the seed of this session's random rolls, or start them over from another seed", maxArgs: 1, handler: commandSeed})
+	r.register(command{name: "run", args: "<script>", description: "Run the commands in a script file, one per line or separated by semicolons, stopping at the first that fails", minArgs: 1, maxArgs: 1, handler: commandRun})
+	r.register(command{name: "exit", aliases: []string{"quit"}, description: "Exit the Pokedex", maxArgs: 0, handler: commandExit})
 	return
 }
 
-func getCommand() (command string) {
-	fmt.Print(lineSeparator)
-	fmt.Print("Pokedex > ")
+// isPromptCommand is whether a lone argument names a prompt command rather than a script, which it can still be if
+// there's a file by that name
+func isPromptCommand(commands *commandRegistry, arg string) (isCommand bool) {
+	if _, ok := commands.find(arg); !ok {
+		return false
+	}
 
-	scanner := bufio.NewScanner(os.Stdin)
-	scanner.Scan()
-	return scanner.Text()
+	info, err := os.Stat(arg)
+	return err != nil || !info.Mode().IsRegular()
 }
 
-/*-------------------------------------------------------------------------------------------------------------------------------------------------------------------*/
-// map & mapb commands
-func printLocations(locations [pokeapi.LocationCount]string) {
-	count := 0
-	for _, name := range locations {
-		if name != "" { count++ }
+func envOrDefault(key string, fallback string) (value string) {
+	if value = os.Getenv(key); value != "" {
+		return value
+	}
+
+	return fallback
+}
+
+func getCommand(editor *lineedit.Editor, isInteractive bool) (command string, err error) {
+	if !isInteractive {
+		return editor.ReadLine("")
 	}
 
-	if count == 0 {
-		fmt.Println("Nothing to explore here...")
+	fmt.Print(lineSeparator)
+	return editor.ReadLine("Pokedex > ")
+}
+
+// printError reports whatever a command returned. PokeAPI failures can come joined together from many goroutines, so only the first few are shown.
+func printError(err error) {
+	var networkErr *pokeapi.NetworkError
+	var statusErr *pokeapi.StatusError
+	var decodeErr *pokeapi.DecodeError
+	var notFoundErr *pokeapi.NotFoundError
+
+	if err == nil {
+		return
+	} else if errors.Is(err, context.Canceled) {
+		fmt.Fprintln(os.Stderr, "\nCancelled.")
+		return
+	} else if errors.Is(err, pokeapi.ErrNoSuchPage) {
+		fmt.Fprintln(os.Stderr, "There's no page of locations with that number!")
+		return
+	} else if !errors.As(err, &networkErr) && !errors.As(err, &statusErr) && !errors.As(err, &decodeErr) && !errors.As(err, &notFoundErr) {
+		fmt.Fprintln(os.Stderr, err)
 		return
 	}
 
-	for _, location := range locations {
-		if location != "" { fmt.Println(location) }
+	const maxShown = 3
+	fmt.Fprintln(os.Stderr, "\nSomething went wrong talking to the PokeAPI:")
+	lines := strings.Split(err.Error(), "\n")
+	for i, line := range lines {
+		if i == maxShown {
+			fmt.Fprintf(os.Stderr, "  ...and %d more\n", len(lines) - maxShown)
+			break
+		}
+		fmt.Fprintln(os.Stderr, "  -", line)
 	}
 
 	return
 }
 
+/*-------------------------------------------------------------------------------------------------------------------------------------------------------------------*/
+// help, exit & run commands
+
+func commandHelp(ctx context.Context, s *session, args []string) (err error) {
+	return s.show(s.commands.help())
+}
+
+func commandExit(ctx context.Context, s *session, args []string) (err error) {
+	s.autosave()
+	s.isExiting = true
+	return nil
+}
+
+func commandRun(ctx context.Context, s *session, args []string) (err error) {
+	script, err := readScript(args[0])
+	if err != nil {
+		return err
+	}
+
+	return s.runScript(ctx, script, false)
+}
+
+/*-------------------------------------------------------------------------------------------------------------------------------------------------------------------*/
+// map & mapb commands
+
+func commandMap(ctx context.Context, s *session, args []string) (err error) {
+	return showLocationPage(ctx, s, "map")
+}
+
+func commandMapBack(ctx context.Context, s *session, args []string) (err error) {
+	return showLocationPage(ctx, s, "mapb")
+}
+
+// showLocationPage moves to the next or previous page of locations. Paging past either end only gets a notice, so that a
+// script walking the whole map doesn't fail at the end of it.
+func showLocationPage(ctx context.Context, s *session, direction string) (err error) {
+	page, err := s.locationCacher(ctx, &s.cache, direction)
+	if errors.Is(err, pokeapi.ErrFirstPage) {
+		fmt.Fprintln(os.Stderr, "No previous locations!")
+		return nil
+	} else if errors.Is(err, pokeapi.ErrLastPage) {
+		fmt.Fprintln(os.Stderr, "You've reached the last page of locations!")
+		return nil
+	}
+
+	if len(page.Locations) > 0 {
+		s.currentLocations = s.locationsInGameVersion(page.Locations)
+		s.currentArea = ""
+		s.wildPokemon = nil
+		if showErr := s.show(locationPageResult{Page: page.Number, TotalPages: page.Total, Locations: s.currentLocations, Version: s.trainer.GameVersion()}); showErr != nil {
+			return showErr
+		}
+	}
+
+	return err
+}
+
 /*-------------------------------------------------------------------------------------------------------------------------------------------------------------------*/
 // explore command
 
-func getAreaPokemon(command string, currentLocations [pokeapi.LocationCount]string, cache pokecache.Cache) (names []string) {
-	commandPieces := strings.Split(command, " ")
-	if len(commandPieces) != 2 {
-		fmt.Print("Usage: explore <area-name>")
-		return
+func commandExplore(ctx context.Context, s *session, args []string) (err error) {
+	location := args[0]
+	if !slices.Contains(s.currentLocations, location) {
+		return errors.New("You're not in this area right now!")
 	}
 
-	location := commandPieces[1]
-	if !slices.Contains(currentLocations[:], location) {
-		fmt.Print("You're not in this area right now!")
-		return
+	// The area may have expired from the cache since the map command listed it
+	if err = s.client.CacheLocationByName(ctx, &s.cache, location); err != nil {
+		return err
+	}
+
+	entry, _ := s.cache.GetLocationByName(location)
+	encounters := s.inGameVersion(entry.Encounters)
+	if version := s.trainer.GameVersion(); len(encounters) == 0 && version != "" {
+		return fmt.Errorf("%s isn't in %s!", location, version)
+	}
+
+	result := newAreaResult(location, encounters)
+	result.Version = s.trainer.GameVersion()
+	s.currentArea = location
+	s.wildPokemon = nil
+	return s.show(result)
+}
+
+/*-------------------------------------------------------------------------------------------------------------------------------------------------------------------*/
+// walk, fish & encounter commands
+
+func commandWalk(ctx context.Context, s *session, args []string) (err error) {
+	return findWildPokemon(ctx, s, "walk")
+}
+
+func commandFish(ctx context.Context, s *session, args []string) (err error) {
+	rod := strings.TrimSuffix(args[0], "-rod")
+	if rod != "old" && rod != "good" && rod != "super" {
+		return &usageError{problem: fmt.Sprintf("There's no %s rod", rod)}
+	}
+
+	return findWildPokemon(ctx, s, rod + "-rod")
+}
+
+func commandEncounter(ctx context.Context, s *session, args []string) (err error) {
+	method := "walk"
+	if len(args) == 1 {
+		method = args[0]
 	}
 
-	return getLocationPokemon(location, cache)
+	return findWildPokemon(ctx, s, method)
 }
 
-func getLocationPokemon(location string, cache pokecache.Cache) (pokemon []string) {
-	for _, entry := range cache.Info {
-		if location == entry.LocationName {
-			return entry.LocationPokemon
+// findWildPokemon rolls a wild pokemon from the current area's encounter table for method, weighted by the chances.
+// Rods only get a bite as often as the area's rate for them, while the other methods keep looking until they find something.
+func findWildPokemon(ctx context.Context, s *session, method string) (err error) {
+	if s.currentArea == "" {
+		return errors.New("You need to explore an area before you can look for pokemon in it!")
+	}
+
+	// The area may have expired from the cache since it was explored
+	if err = s.client.CacheLocationByName(ctx, &s.cache, s.currentArea); err != nil {
+		return err
+	}
+	entry, _ := s.cache.GetLocationByName(s.currentArea)
+
+	slots := encounterSlots(s.inGameVersion(entry.Encounters), method)
+	if len(slots) == 0 {
+		return fmt.Errorf("There are no pokemon to find with %s in %s!", method, s.currentArea)
+	}
+
+	s.wildPokemon = nil
+	result := encounterResult{Area: s.currentArea, Method: method}
+	if strings.HasSuffix(method, "-rod") && !game.RollBite(methodRate(s.methodRatesInGameVersion(entry.MethodRates), method), s.random.Intn) {
+		return s.show(result)
+	}
+
+	wild, isFound := game.RollEncounter(slots, s.random.Intn)
+	if isFound {
+		s.wildPokemon = &wild
+		result.HasAppeared = true
+		result.Pokemon = wild.Pokemon
+		result.Level = wild.Level
+	}
+	return s.show(result)
+}
+
+// encounterSlots is the encounter table for one method, each pokemon with its best chance and widest levels across the versions given
+func encounterSlots(encounters []pokecache.Encounter, method string) (slots []game.EncounterSlot) {
+	for _, encounter := range encounters {
+		if encounter.Method != method {
+			continue
+		}
+
+		i := slices.IndexFunc(slots, func(slot game.EncounterSlot) bool {
+			return slot.Pokemon == encounter.Pokemon
+		})
+		if i == -1 {
+			slots = append(slots, game.EncounterSlot{Pokemon: encounter.Pokemon, Chance: encounter.Chance, MinLevel: encounter.MinLevel, MaxLevel: encounter.MaxLevel})
+			continue
 		}
+
+		slots[i].Chance = max(slots[i].Chance, encounter.Chance)
+		slots[i].MinLevel = min(slots[i].MinLevel, encounter.MinLevel)
+		slots[i].MaxLevel = max(slots[i].MaxLevel, encounter.MaxLevel)
 	}
 
-	return nil
+	return slots
 }
 
-func printAreaPokemon(pokemon []string) {
-	for _, name := range pokemon {
-		fmt.Println("  -", name)
+// methodRate is the best rate of the method across the versions given, or 100 for an area that doesn't say
+func methodRate(methodRates []pokecache.MethodRate, method string) (rate int) {
+	rate = -1
+	for _, methodRate := range methodRates {
+		if methodRate.Method == method {
+			rate = max(rate, methodRate.Rate)
+		}
 	}
 
-	return
+	if rate == -1 {
+		return 100
+	}
+	return rate
 }
 
 /*-------------------------------------------------------------------------------------------------------------------------------------------------------------------*/
 // catch command
 
-func catchPokemon(cache pokecache.Cache, command string, currentPokemon []string) {
-	commandPieces := strings.Split(command, " ")
-	if len(commandPieces) != 2 {
-		fmt.Println("Usage: catch <name of pokemon>")
-		return
+func commandCatch(ctx context.Context, s *session, args []string) (err error) {
+	flags := flag.NewFlagSet("catch", flag.ContinueOnError)
+	ball := flags.String("ball", game.DefaultBall, "")
+	hpPercent := flags.Float64("hp", 100, "")
+	statusName := flags.String("status", string(game.StatusNone), "")
+	showOdds := flags.Bool("odds", false, "")
+	positional, err := parseFlags(flags, args)
+	if err != nil {
+		return err
+	} else if len(positional) != 1 {
+		return &usageError{}
+	} else if *hpPercent <= 0 || *hpPercent > 100 {
+		return &usageError{problem: "--hp is the percentage of its HP the pokemon has left, above 0 and at most 100"}
+	}
+	status, err := game.ParseStatus(*statusName)
+	if err != nil {
+		return &usageError{problem: "Can't catch it like that, " + err.Error()}
 	}
 
-	pokemonToCatch := commandPieces[1]
-	if !slices.Contains(currentPokemon, pokemonToCatch) {
-		fmt.Printf("%s isn't here!\n", pokemonToCatch)
-		return
+	pokemonToCatch := positional[0]
+	if s.wildPokemon == nil {
+		return errors.New("There's no wild pokemon to catch, walk or fish to find one first!")
+	} else if s.wildPokemon.Pokemon != pokemonToCatch {
+		return fmt.Errorf("%s isn't here, but a wild %s is!", pokemonToCatch, s.wildPokemon.Pokemon)
 	}
 
-	fmt.Printf("Throwing a Pokeball at %s...\n", pokemonToCatch)
-	baseExperience := cache.Pokemon[pokemonToCatch].BaseExperience
+	if !s.trainer.HasRoom() {
+		return errors.New("Your party and every box are full, release a pokemon before catching another!")
+	} else if s.trainer.ItemCount(*ball) == 0 {
+		return fmt.Errorf("You don't have any %ss left!", displayName(*ball))
+	}
+	if err = s.client.CacheItem(ctx, &s.cache, *ball); err != nil {
+		return err
+	}
+	if item, _ := s.cache.GetItem(*ball); !isBallCategory(item.Category) {
+		return fmt.Errorf("You can't catch pokemon with a %s!", displayName(*ball))
+	}
 
-	if rand.Intn(100000) > baseExperience {
-		fmt.Println(pokemonToCatch, "was caught!")
-		cachedPokemon := cache.Pokemon[pokemonToCatch]
-		cachedPokemon.IsCaught = true
-		cache.Pokemon[pokemonToCatch] = cachedPokemon  // Make a cache method for this, since it modifies the cache?
-	} else {
-		fmt.Println(pokemonToCatch, "escaped!")
+	if err = s.client.CacheSpecies(ctx, &s.cache, pokemonToCatch); err != nil {
+		return err
 	}
+	data, _ := s.cache.GetPokemon(pokemonToCatch)
 
-	return
+	attempt := game.CatchAttempt{CaptureRate: data.CaptureRate, RemainingHP: *hpPercent / 100, Status: status, Ball: *ball}
+	if err = attempt.Validate(); err != nil {
+		return fmt.Errorf("Can't catch %s, %w", pokemonToCatch, err)
+	}
+	if err = s.trainer.UseItem(*ball); err != nil {
+		return fmt.Errorf("Can't throw it, %w", err)
+	}
+
+	outcome := attempt.Throw(s.random.Intn)
+	result := catchResult{Pokemon: pokemonToCatch, Level: s.wildPokemon.Level, Ball: attempt.Ball, BallsLeft: s.trainer.ItemCount(*ball), Shakes: outcome.Shakes, IsCaught: outcome.IsCaught}
+	if outcome.IsCaught {
+		individual := game.RollIndividual(s.wildPokemon.Level, data.GenderRate, s.random.Intn)
+		caught, err := s.trainer.Catch(player.Pokemon{
+			Species: pokemonToCatch,
+			Level: individual.Level,
+			IVs: &individual.IVs,
+			Nature: individual.Nature,
+			Gender: individual.Gender,
+			IsShiny: individual.IsShiny,
+			Ball: attempt.Ball,
+			Location: s.currentArea,
+		})
+		if err != nil {
+			return fmt.Errorf("Can't keep %s, %w", pokemonToCatch, err)
+		}
+		result.ID = caught.ID
+		result.IsShiny = caught.IsShiny
+		result.Box, _ = s.trainer.Locate(caught.ID)
+		s.wildPokemon = nil
+	}
+	if *showOdds {
+		odds := attempt.Odds()
+		result.Odds = &odds
+	}
+	err = s.show(result)
+
+	s.autosave()
+	return err
+}
+
+// isBallCategory is whether a PokeAPI item category is one of Poke Balls
+func isBallCategory(category string) (isBall bool) {
+	return category == "standard-balls" || category == "special-balls" || category == "apricorn-balls"
+}
+
+/*-------------------------------------------------------------------------------------------------------------------------------------------------------------------*/
+// bag command
+
+// commandBag lists every item, even when some descriptions can't be fetched
+func commandBag(ctx context.Context, s *session, args []string) (err error) {
+	result := bagResult{Items: []bagEntry{}}
+	var errs []error
+	for _, item := range s.trainer.GetBag() {
+		entry := bagEntry{Item: item.Item, Count: item.Count}
+		if err = s.client.CacheItem(ctx, &s.cache, item.Item); err != nil {
+			errs = append(errs, err)
+		}
+		if data, ok := s.cache.GetItem(item.Item); ok {
+			entry.Category = data.Category
+			entry.Description = data.Effect
+		}
+		result.Items = append(result.Items, entry)
+	}
+
+	if err = s.show(result); err != nil {
+		return err
+	}
+	return errors.Join(errs...)
 }
 
 /*-------------------------------------------------------------------------------------------------------------------------------------------------------------------*/
 // inspect command
 
-func inspectPokemon(cache pokecache.Cache, command string) {
-	commandPieces := strings.Split(command, " ")
-	if len(commandPieces) != 2 {
-		fmt.Println("Usage: inspect <name of pokemon>")
-		return
+func commandInspect(ctx context.Context, s *session, args []string) (err error) {
+	pokemon, err := findOwnedPokemon(s, args[0])
+	if err != nil {
+		return err
 	}
 
-	pokemonToInspect := commandPieces[1]
-	if _, ok := cache.Pokemon[pokemonToInspect]; !ok {
-		fmt.Println("You haven't discovered a pokemon named", pokemonToInspect, "yet!")
-		return
+	// Pokemon caught in an earlier session aren't in the cache until someone asks about them
+	if err = s.client.CachePokemon(ctx, &s.cache, pokemon.Species); err != nil {
+		return err
 	}
 
-	if !cache.Pokemon[pokemonToInspect].IsCaught {
-		fmt.Println("You haven't caught a", pokemonToInspect, "yet!")
-		return
+	data, _ := s.cache.GetPokemon(pokemon.Species)
+	return s.show(newPokemonResult(pokemon, data))
+}
+
+// findOwnedPokemon picks one of the trainer's pokemon by its ID, nickname or species, as long as only one matches
+func findOwnedPokemon(s *session, name string) (pokemon player.Pokemon, err error) {
+	if id, errID := strconv.Atoi(name); errID == nil {
+		if pokemon, isFound := s.trainer.GetPokemon(id); isFound {
+			return pokemon, nil
+		}
+		return pokemon, fmt.Errorf("You don't have a pokemon with ID %d!", id)
 	}
 
-	printPokemonInformation(cache, pokemonToInspect)
-	return
+	matches := s.trainer.FindPokemon(name)
+	if len(matches) == 0 {
+		return pokemon, fmt.Errorf("You haven't caught a %s yet!", name)
+	} else if len(matches) > 1 {
+		var ids []string
+		for _, match := range matches {
+			ids = append(ids, strconv.Itoa(match.ID))
+		}
+		return pokemon, fmt.Errorf("You have %d %s, pick one by its ID: %s", len(matches), name, strings.Join(ids, ", "))
+	}
+
+	return matches[0], nil
 }
 
-func printPokemonInformation(cache pokecache.Cache, pokemon string) {
-	fmt.Println("Name:", pokemon)
-	fmt.Println("Height:", cache.Pokemon[pokemon].Height)
-	fmt.Println("Weight:", cache.Pokemon[pokemon].Weight)
-	fmt.Println("Stats:")
-	fmt.Println("  -hp:", cache.Pokemon[pokemon].HP)
-	fmt.Println("  -attack:", cache.Pokemon[pokemon].Attack)
-	fmt.Println("  -defense:", cache.Pokemon[pokemon].Defense)
-	fmt.Println("  -special-attack:", cache.Pokemon[pokemon].SpecialAttack)
-	fmt.Println("  -special-defense:", cache.Pokemon[pokemon].SpecialDefense)
-	fmt.Println("  -Speed:", cache.Pokemon[pokemon].Speed)
-	fmt.Println("Types:")
+/*-------------------------------------------------------------------------------------------------------------------------------------------------------------------*/
+// pokedex & nickname commands
 
-	for _, type_ := range cache.Pokemon[pokemon].Types {
-		fmt.Println("  -" + type_)
+func commandPokedex(ctx context.Context, s *session, args []string) (err error) {
+	return s.show(newPokedexResult(s.trainer.GetCaughtPokemon(), s.trainer.GetAllPokemon()))
+}
+
+func commandNickname(ctx context.Context, s *session, args []string) (err error) {
+	pokemon, err := findOwnedPokemon(s, args[0])
+	if err != nil {
+		return err
 	}
 
-	return
+	nickname := strings.Join(args[1:], " ")
+	if _, err = strconv.Atoi(nickname); err == nil {
+		return errors.New("A nickname can't be a number, it would look like an ID!")
+	}
+	if err = s.trainer.SetNickname(pokemon.ID, nickname); err != nil {
+		return fmt.Errorf("Can't do that, %w", err)
+	}
+	s.autosave()
+
+	return s.show(nicknameResult{ID: pokemon.ID, Pokemon: pokemon.Species, Nickname: nickname})
 }
 
 /*-------------------------------------------------------------------------------------------------------------------------------------------------------------------*/
-// pokedex command
+// party, box, deposit, withdraw, swap & release commands
 
-func printCaughtPokemon(cache pokecache.Cache) {
-	caughtPokemon := cache.GetCaughtPokemon()
+func commandParty(ctx context.Context, s *session, args []string) (err error) {
+	return s.show(newPartyResult(s.trainer.GetParty()))
+}
 
-	if len(caughtPokemon) == 0 {
-		fmt.Println("You haven't caught any pokemon yet!")
-		return
+func commandBox(ctx context.Context, s *session, args []string) (err error) {
+	box := 1
+	if len(args) == 1 {
+		if box, err = strconv.Atoi(args[0]); err != nil {
+			return &usageError{problem: fmt.Sprintf("%q isn't a box number", args[0])}
+		}
 	}
 
-	fmt.Println("Your Pokedex:")
-	for _, name := range caughtPokemon {
-		fmt.Println("  -", name)
+	pokemon, err := s.trainer.GetBox(box)
+	if err != nil {
+		return fmt.Errorf("Can't open that box, %w", err)
 	}
+	return s.show(newBoxResult(box, pokemon))
+}
 
-	return
-}
\ No newline at end of file
+func commandDeposit(ctx context.Context, s *session, args []string) (err error) {
+	box := 0
+	if len(args) == 2 {
+		if box, err = strconv.Atoi(args[1]); err != nil || box == 0 {
+			return &usageError{problem: fmt.Sprintf("%q isn't a box number", args[1])}
+		}
+	}
+	pokemon, err := findOwnedPokemon(s, args[0])
+	if err != nil {
+		return err
+	}
+
+	if box, err = s.trainer.Deposit(pokemon.ID, box); err != nil {
+		return fmt.Errorf("Can't deposit %s, %w", pokemon.Species, err)
+	}
+	s.autosave()
+
+	return s.show(depositResult{ID: pokemon.ID, Pokemon: pokemon.Species, Box: box})
+}
+
+func commandWithdraw(ctx context.Context, s *session, args []string) (err error) {
+	pokemon, err := findOwnedPokemon(s, args[0])
+	if err != nil {
+		return err
+	}
+
+	box, err := s.trainer.Withdraw(pokemon.ID)
+	if err != nil {
+		return fmt.Errorf("Can't withdraw %s, %w", pokemon.Species, err)
+	}
+	s.autosave()
+
+	return s.show(withdrawResult{ID: pokemon.ID, Pokemon: pokemon.Species, Box: box})
+}
+
+func commandSwap(ctx context.Context, s *session, args []string) (err error) {
+	first, err := findOwnedPokemon(s, args[0])
+	if err != nil {
+		return err
+	}
+	second, err := findOwnedPokemon(s, args[1])
+	if err != nil {
+		return err
+	}
+
+	if err = s.trainer.Swap(first.ID, second.ID); err != nil {
+		return fmt.Errorf("Can't swap them, %w", err)
+	}
+	s.autosave()
+
+	return s.show(swapResult{Swapped: []pokemonSummary{newPokemonSummary(first), newPokemonSummary(second)}})
+}
+
+func commandRelease(ctx context.Context, s *session, args []string) (err error) {
+	pokemon, err := findOwnedPokemon(s, args[0])
+	if err != nil {
+		return err
+	}
+
+	released, err := s.trainer.Release(pokemon.ID)
+	if err != nil {
+		return fmt.Errorf("Can't release %s, %w", pokemon.Species, err)
+	}
+	s.autosave()
+
+	return s.show(releaseResult{ID: released.ID, Pokemon: released.Species})
+}
+
+/*-------------------------------------------------------------------------------------------------------------------------------------------------------------------*/
+// cache command
+
+func commandCache(ctx context.Context, s *session, args []string) (err error) {
+	if len(args) == 0 {
+		return s.show(newCacheResult(s.cache, s.diskCache))
+	} else if args[0] == "purge" {
+		return purgeDiskCache(s, s.diskCache)
+	}
+
+	return &usageError{}
+}
+
+func newCacheResult(cache pokecache.Cache, diskCache *pokecache.DiskCache) (r cacheResult) {
+	stats := cache.Stats()
+	r.InMemory.Locations = newCacheStats(stats.Locations)
+	r.InMemory.Pokemon = newCacheStats(stats.Pokemon)
+	r.InMemory.Items = newCacheStats(stats.Items)
+	if diskCache == nil {
+		return r
+	}
+
+	r.OnDisk.IsEnabled = true
+	diskStats, err := diskCache.Stats()
+	if err != nil {
+		r.OnDisk.Error = err.Error()
+		return r
+	}
+
+	r.OnDisk.Dir = diskStats.Dir
+	r.OnDisk.Responses = diskStats.Entries
+	r.OnDisk.Bytes = diskStats.Bytes
+	return r
+}
+
+func purgeDiskCache(s *session, diskCache *pokecache.DiskCache) (err error) {
+	if diskCache == nil {
+		return errors.New("The on-disk cache is turned off for this session")
+	}
+
+	removed, err := diskCache.Purge()
+	if showErr := s.show(purgeResult{Removed: removed}); showErr != nil {
+		return showErr
+	}
+	if err != nil {
+		return fmt.Errorf("Some could not be removed: %w", err)
+	}
+
+	return nil
+}
+
+/*-------------------------------------------------------------------------------------------------------------------------------------------------------------------*/
+// save & load commands
+
+func commandSave(ctx context.Context, s *session, args []string) (err error) {
+	savePath := s.savePath
+	if len(args) == 1 {
+		savePath = args[0]
+	}
+
+	if err = player.Save(savePath, s.trainer); err != nil {
+		return fmt.Errorf("Couldn't save your Pokedex: %w", err)
+	}
+
+	s.savePath = savePath
+	return s.show(savedResult{SavedTo: savePath})
+}
+
+func commandLoad(ctx context.Context, s *session, args []string) (err error) {
+	savePath := args[0]
+	if _, err = os.Stat(savePath); err != nil {
+		return fmt.Errorf("Couldn't open save file: %w", err)
+	}
+
+	trainer, err := player.Load(savePath)
+	if err != nil {
+		return fmt.Errorf("Couldn't load save file: %w", err)
+	}
+
+	// The rolls carry on from the seed recorded in the save, so the save still says how they can be replayed. A save from
+	// before seeds were recorded takes the session's.
+	s.trainer = trainer
+	if seed := trainer.Seed(); seed != 0 {
+		s.setSeed(seed)
+	} else {
+		s.trainer.SetSeed(s.seed)
+	}
+	s.savePath = savePath
+
+	// The map, the area and the wild pokemon were found in the previous trainer's game version, so start over from page 1
+	s.currentLocations = nil
+	s.currentArea = ""
+	s.wildPokemon = nil
+	s.locationCacher = s.client.LocationCacher()
+	return s.show(loadedResult{LoadedFrom: savePath, PokemonCaught: len(trainer.GetCaughtPokemon())})
+}
+
+/*-------------------------------------------------------------------------------------------------------------------------------------------------------------------*/
+// format command
+
+func commandFormat(ctx context.Context, s *session, args []string) (err error) {
+	if len(args) == 1 {
+		format, err := parseOutputFormat(args[0])
+		if err != nil {
+			return err
+		}
+		s.setFormat(format)
+	}
+
+	return s.show(formatResult{Format: s.format})
+}
+
+/*-------------------------------------------------------------------------------------------------------------------------------------------------------------------*/
+// version command
+
+func commandVersion(ctx context.Context, s *session, args []string) (err error) {
+	if len(args) == 1 {
+		if err = s.setGameVersion(ctx, args[0]); err != nil {
+			return err
+		}
+
+		// What was found in the area so far may not be in the new version, and the map has to be looked at again
+		s.currentArea = ""
+		s.wildPokemon = nil
+		s.currentLocations = s.locationsInGameVersion(s.currentLocations)
+		s.autosave()
+	}
+
+	result := versionResult{Version: s.trainer.GameVersion()}
+	if result.Version == "" {
+		result.Version = allVersions
+	}
+	return s.show(result)
+}
+
+/*-------------------------------------------------------------------------------------------------------------------------------------------------------------------*/
+// seed command
+
+func commandSeed(ctx context.Context, s *session, args []string) (err error) {
+	if len(args) == 1 {
+		seed, err := strconv.ParseInt(args[0], 10, 64)
+		if err != nil || seed == 0 {
+			return &usageError{problem: fmt.Sprintf("%q isn't a seed, use any whole number but 0", args[0])}
+		}
+		s.setSeed(seed)
+	}
+
+	return s.show(seedResult{Seed: s.seed})
+}
+
+// newSeed picks a seed from the clock, never 0 since that means "pick one"
+func newSeed() (seed int64) {
+	if seed = time.Now().UnixNano(); seed == 0 {
+		seed = 1
+	}
+
+	return seed
+}
+
+/*-------------------------------------------------------------------------------------------------------------------------------------------------------------------*/
+// tab completion
+
+func completeArea(s *session, argIndex int) (candidates []string) {
+	if argIndex == 0 {
+		return s.currentLocations
+	}
+	return nil
+}
+
+func completeWild(s *session, argIndex int) (candidates []string) {
+	if argIndex == 0 && s.wildPokemon != nil {
+		return []string{s.wildPokemon.Pokemon}
+	}
+	return nil
+}
+
+func completeRod(s *session, argIndex int) (candidates []string) {
+	if argIndex == 0 {
+		return []string{"old", "good", "super"}
+	}
+	return nil
+}
+
+// completeMethod offers the encounter methods of the area being explored, as far as the cache knows them
+func completeMethod(s *session, argIndex int) (candidates []string) {
+	if argIndex != 0 {
+		return nil
+	}
+
+	entry, _ := s.cache.GetLocationByName(s.currentArea)
+	for _, encounter := range s.inGameVersion(entry.Encounters) {
+		if !slices.Contains(candidates, encounter.Method) {
+			candidates = append(candidates, encounter.Method)
+		}
+	}
+	return candidates
+}
+
+// completeVersion offers every version the area being explored has encounters in
+func completeVersion(s *session, argIndex int) (candidates []string) {
+	if argIndex != 0 {
+		return nil
+	}
+
+	candidates = []string{allVersions}
+	entry, _ := s.cache.GetLocationByName(s.currentArea)
+	for _, encounter := range entry.Encounters {
+		if !slices.Contains(candidates, encounter.Version) {
+			candidates = append(candidates, encounter.Version)
+		}
+	}
+	return candidates
+}
+
+func completeCaught(s *session, argIndex int) (candidates []string) {
+	if argIndex != 0 {
+		return nil
+	}
+
+	return pokemonNames(s.trainer.GetAllPokemon())
+}
+
+func completeSwap(s *session, argIndex int) (candidates []string) {
+	if argIndex > 1 {
+		return nil
+	}
+
+	return pokemonNames(s.trainer.GetAllPokemon())
+}
+
+// pokemonNames are the nicknames and species that pick out the pokemon, leaving out nicknames with spaces in them
+func pokemonNames(pokemon []player.Pokemon) (names []string) {
+	for _, p := range pokemon {
+		for _, name := range []string{p.Nickname, p.Species} {
+			if name != "" && !strings.Contains(name, " ") && !slices.Contains(names, name) {
+				names = append(names, name)
+			}
+		}
+	}
+	return names
+}
+
+func completeParty(s *session, argIndex int) (candidates []string) {
+	if argIndex != 0 {
+		return nil
+	}
+
+	return pokemonNames(s.trainer.GetParty())
+}
+
+func completeCache(s *session, argIndex int) (candidates []string) {
+	if argIndex == 0 {
+		return []string{"purge"}
+	}
+	return nil
+}
+
+func completeFormat(s *session, argIndex int) (candidates []string) {
+	if argIndex != 0 {
+		return nil
+	}
+
+	for _, format := range outputFormats {
+		candidates = append(candidates, string(format))
+	}
+	return candidates
+}
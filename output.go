@@ -0,0 +1,226 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// outputFormat is how command results are shown: as text for people, or as JSON or YAML for scripts
+type outputFormat string
+
+const (
+	formatTable outputFormat = "table"
+	formatJSON outputFormat = "json"  // One JSON object per line, one line per command
+	formatYAML outputFormat = "yaml"  // One YAML document per command, each starting with ---
+)
+
+var outputFormats = []outputFormat{formatTable, formatJSON, formatYAML}
+
+// result is what a command produces. Every result can write itself as text, while its JSON and YAML come from its
+// json tags, which are part of the CLI's interface: fields may be added, but never renamed or removed.
+type result interface {
+	writeText(w io.Writer)
+}
+
+// renderer writes command results in one output format
+type renderer interface {
+	render(w io.Writer, r result) error
+}
+
+type tableRenderer struct{}
+
+type jsonRenderer struct{}
+
+type yamlRenderer struct{}
+
+// yamlNode is a JSON value read back token by token, so that YAML keeps the field order of the JSON
+type yamlNode struct {
+	keys []string  // Only for mappings
+	children []*yamlNode  // Values of a mapping or items of a sequence
+	scalar string  // Already formatted as YAML, only for scalars
+	isMapping bool
+	isSequence bool
+}
+
+/*-------------------------------------------------------------------------------------------------------------------------------------------------------------------*/
+
+func (tableRenderer) render(w io.Writer, r result) (err error) {
+	r.writeText(w)
+	return nil
+}
+
+func (jsonRenderer) render(w io.Writer, r result) (err error) {
+	encoder := json.NewEncoder(w)
+	encoder.SetEscapeHTML(false)
+	return encoder.Encode(r)
+}
+
+func (yamlRenderer) render(w io.Writer, r result) (err error) {
+	body, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	node, err := readYAMLNode(decoder)
+	if err != nil {
+		return err
+	}
+
+	var document strings.Builder
+	document.WriteString("---\n")
+	if node.isEmpty() {
+		document.WriteString(node.inline() + "\n")
+	} else {
+		node.writeBlock(&document, "")
+	}
+
+	_, err = io.WriteString(w, document.String())
+	return err
+}
+
+func readYAMLNode(decoder *json.Decoder) (node *yamlNode, err error) {
+	token, err := decoder.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	node = &yamlNode{}
+	switch value := token.(type) {
+	case json.Delim:
+		node.isMapping = value == '{'
+		node.isSequence = value == '['
+		for decoder.More() {
+			if node.isMapping {
+				key, err := decoder.Token()
+				if err != nil {
+					return nil, err
+				}
+				node.keys = append(node.keys, yamlString(key.(string)))
+			}
+
+			child, err := readYAMLNode(decoder)
+			if err != nil {
+				return nil, err
+			}
+			node.children = append(node.children, child)
+		}
+		_, err = decoder.Token()  // The closing } or ]
+		return node, err
+	case string:
+		node.scalar = yamlString(value)
+	case json.Number:
+		node.scalar = value.String()
+	case bool:
+		node.scalar = strconv.FormatBool(value)
+	case nil:
+		node.scalar = "null"
+	}
+
+	return node, nil
+}
+
+func (n *yamlNode) isEmpty() (isEmpty bool) {
+	return (n.isMapping || n.isSequence) && len(n.children) == 0
+}
+
+// inline is the node written on the same line as its key or dash, which only works for scalars and empty collections
+func (n *yamlNode) inline() (text string) {
+	if n.isMapping {
+		return "{}"
+	} else if n.isSequence {
+		return "[]"
+	}
+
+	return n.scalar
+}
+
+// writeBlock writes a non-empty mapping or sequence with every line starting at indent
+func (n *yamlNode) writeBlock(out *strings.Builder, indent string) {
+	for i, child := range n.children {
+		prefix := indent + "- "
+		if n.isMapping {
+			prefix = indent + n.keys[i] + ":"
+		}
+
+		if !child.isEmpty() && (child.isMapping || child.isSequence) && n.isSequence {
+			// The first line of the item goes after the dash, the rest line up under it
+			var item strings.Builder
+			child.writeBlock(&item, indent + "  ")
+			out.WriteString(prefix + strings.TrimPrefix(item.String(), indent + "  "))
+		} else if !child.isEmpty() && (child.isMapping || child.isSequence) {
+			out.WriteString(prefix + "\n")
+			child.writeBlock(out, indent + "  ")
+		} else if n.isMapping {
+			out.WriteString(prefix + " " + child.inline() + "\n")
+		} else {
+			out.WriteString(prefix + child.inline() + "\n")
+		}
+	}
+
+	return
+}
+
+// yamlString leaves plain words, names and sentences alone, and quotes anything YAML could read as another type
+func yamlString(value string) (scalar string) {
+	isPlain := value != "" && strings.TrimSpace(value) == value
+	for i, char := range value {
+		isWordChar := char >= 'a' && char <= 'z' || char >= 'A' && char <= 'Z' || char >= '0' && char <= '9' || char == '_' || char == '/' || char == '.'
+		if !isWordChar && (i == 0 || (char != '-' && char != ' ' && char != ',' && char != '\'' && char != '!' && char != '?')) {
+			isPlain = false
+			break
+		}
+	}
+
+	switch strings.ToLower(value) {
+	case "true", "false", "yes", "no", "on", "off", "y", "n", "null", "~", ".inf", ".nan":
+		isPlain = false
+	}
+	if _, err := strconv.ParseFloat(value, 64); err == nil {
+		isPlain = false
+	} else if _, err := strconv.ParseInt(value, 0, 64); err == nil {
+		isPlain = false
+	}
+
+	if isPlain {
+		return value
+	}
+	return strconv.Quote(value)
+}
+
+func parseOutputFormat(name string) (format outputFormat, err error) {
+	for _, format = range outputFormats {
+		if string(format) == name {
+			return format, nil
+		}
+	}
+
+	return "", fmt.Errorf("Unknown output format %q, choose one of %s", name, outputFormatNames())
+}
+
+func outputFormatNames() (names string) {
+	var formats []string
+	for _, format := range outputFormats {
+		formats = append(formats, string(format))
+	}
+
+	return strings.Join(formats, ", ")
+}
+
+/*==================================================================================================================================*/
+
+func newRenderer(format outputFormat) (r renderer) {
+	switch format {
+	case formatJSON:
+		return jsonRenderer{}
+	case formatYAML:
+		return yamlRenderer{}
+	}
+
+	return tableRenderer{}
+}
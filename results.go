@@ -0,0 +1,729 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"time"
+	"github.com/CRowland4/pokedexcli/internal/game"
+	"github.com/CRowland4/pokedexcli/internal/pokecache"
+	"github.com/CRowland4/pokedexcli/internal/player"
+)
+
+// helpResult is the output of help: {"commands": [{"name": "nickname", "aliases": [], "args": "<pokemon> [nickname]", "description": "..."}]}
+type helpResult struct {
+	Commands []commandSummary `json:"commands"`
+}
+
+type commandSummary struct {
+	Name string `json:"name"`
+	Aliases []string `json:"aliases"`
+	Args string `json:"args"`  // Empty for commands that take none
+	Description string `json:"description"`
+}
+
+// locationPageResult is the output of map and mapb: {"page": 1, "total_pages": 52, "locations": ["canalave-city-area", ...]}
+// When a game version is played, only the page's areas in that version are listed and version is set.
+type locationPageResult struct {
+	Page int `json:"page"`
+	TotalPages int `json:"total_pages"`
+	Locations []string `json:"locations"`
+	Version string `json:"version,omitempty"`
+}
+
+// areaResult is the output of explore and area:
+// {"area": "canalave-city-area", "pokemon": ["tentacool", ...], "encounters": [{"pokemon": "tentacool", "method": "surf",
+// "chance": 60, "min_level": 20, "max_level": 30, "versions": ["diamond", "pearl"]}, ...]}
+// Each encounter is one pokemon met with one method, and chance is the percent of that method's encounters that are
+// the pokemon, in whichever of the versions it is highest.
+type areaResult struct {
+	Area string `json:"area"`
+	Pokemon []string `json:"pokemon"`
+	Encounters []encounterSummary `json:"encounters"`
+	Version string `json:"version,omitempty"`  // The game version played, whose encounters are the only ones listed
+}
+
+type encounterSummary struct {
+	Pokemon string `json:"pokemon"`
+	Method string `json:"method"`
+	Chance int `json:"chance"`
+	MinLevel int `json:"min_level"`
+	MaxLevel int `json:"max_level"`
+	Versions []string `json:"versions"`
+}
+
+// encounterResult is the output of walk, fish and encounter. pokemon and level are left out when nothing appeared.
+// {"area": "canalave-city-area", "method": "old-rod", "appeared": true, "pokemon": "magikarp", "level": 7}
+type encounterResult struct {
+	Area string `json:"area"`
+	Method string `json:"method"`
+	HasAppeared bool `json:"appeared"`
+	Pokemon string `json:"pokemon,omitempty"`
+	Level int `json:"level,omitempty"`
+}
+
+// catchResult is the output of catch: {"pokemon": "pikachu", "level": 5, "ball": "poke-ball", "balls_left": 9, "shakes": 3, "caught": true, "odds": 0.43}
+// shakes is how many times the ball shook, from 0 to 3, and odds, from 0 to 1, is only there when --odds was given.
+type catchResult struct {
+	Pokemon string `json:"pokemon"`
+	Level int `json:"level"`
+	Ball string `json:"ball"`
+	BallsLeft int `json:"balls_left"`
+	Shakes int `json:"shakes"`
+	IsCaught bool `json:"caught"`
+	Odds *float64 `json:"odds,omitempty"`
+	ID int `json:"id,omitempty"`  // The caught pokemon's
+	IsShiny bool `json:"shiny,omitempty"`
+	Box int `json:"box,omitempty"`  // Where the caught pokemon went when the party was full
+}
+
+// pokemonResult is the output of inspect and of the pokemon subcommand. stats are the species' base stats, and inspect
+// adds the pokemon's own id, level, nature, gender, ivs and the actual_stats they make. Anything unknown is left out,
+// as are nickname, caught_at (RFC 3339), caught_in and caught_with for a pokemon not caught yet.
+type pokemonResult struct {
+	Name string `json:"name"`
+	Nickname string `json:"nickname,omitempty"`
+	CaughtAt *time.Time `json:"caught_at,omitempty"`
+	CaughtIn string `json:"caught_in,omitempty"`
+	Height int `json:"height"`
+	Weight int `json:"weight"`
+	Stats pokemonStats `json:"stats"`
+	Types []string `json:"types"`
+	ID int `json:"id,omitempty"`
+	Level int `json:"level,omitempty"`
+	Nature string `json:"nature,omitempty"`
+	Gender string `json:"gender,omitempty"`
+	IsShiny bool `json:"shiny,omitempty"`
+	CaughtWith string `json:"caught_with,omitempty"`
+	IVs *pokemonStats `json:"ivs,omitempty"`
+	ActualStats *pokemonStats `json:"actual_stats,omitempty"`
+}
+
+type pokemonStats struct {
+	HP int `json:"hp"`
+	Attack int `json:"attack"`
+	Defense int `json:"defense"`
+	SpecialAttack int `json:"special_attack"`
+	SpecialDefense int `json:"special_defense"`
+	Speed int `json:"speed"`
+}
+
+// pokedexResult is the output of pokedex, one entry per species caught with how many of it the trainer has:
+// {"pokemon": [{"species": "zubat", "caught_at": "...", "caught_in": "...", "owned": 2}]}
+// nickname is only there for species nicknamed before each pokemon had its own.
+type pokedexResult struct {
+	Pokemon []pokedexEntry `json:"pokemon"`
+}
+
+type pokedexEntry struct {
+	Species string `json:"species"`
+	Nickname string `json:"nickname,omitempty"`
+	CaughtAt *time.Time `json:"caught_at,omitempty"`
+	CaughtIn string `json:"caught_in,omitempty"`
+	Owned int `json:"owned"`
+}
+
+// partyResult is the output of party: {"party": [{"id": 3, "species": "zubat", "nickname": "Zu", "level": 5}, ...]}
+// nickname and level are left out when the pokemon has none, and shiny when it isn't.
+type partyResult struct {
+	Party []pokemonSummary `json:"party"`
+}
+
+// boxResult is the output of box: {"box": 1, "capacity": 30, "pokemon": [{"id": 9, "species": "magikarp", "level": 7}, ...]}
+type boxResult struct {
+	Box int `json:"box"`
+	Capacity int `json:"capacity"`
+	Pokemon []pokemonSummary `json:"pokemon"`
+}
+
+type pokemonSummary struct {
+	ID int `json:"id"`
+	Species string `json:"species"`
+	Nickname string `json:"nickname,omitempty"`
+	Level int `json:"level,omitempty"`
+	IsShiny bool `json:"shiny,omitempty"`
+}
+
+// depositResult is the output of deposit: {"id": 3, "pokemon": "zubat", "box": 1}
+type depositResult struct {
+	ID int `json:"id"`
+	Pokemon string `json:"pokemon"`
+	Box int `json:"box"`
+}
+
+// withdrawResult is the output of withdraw, with the box the pokemon came from: {"id": 3, "pokemon": "zubat", "box": 1}
+type withdrawResult struct {
+	ID int `json:"id"`
+	Pokemon string `json:"pokemon"`
+	Box int `json:"box"`
+}
+
+// swapResult is the output of swap: {"swapped": [{"id": 3, "species": "zubat", ...}, {"id": 9, "species": "magikarp", ...}]}
+type swapResult struct {
+	Swapped []pokemonSummary `json:"swapped"`
+}
+
+// releaseResult is the output of release: {"id": 3, "pokemon": "zubat"}
+type releaseResult struct {
+	ID int `json:"id"`
+	Pokemon string `json:"pokemon"`
+}
+
+// bagResult is the output of bag: {"items": [{"item": "poke-ball", "count": 9, "category": "standard-balls", "description": "..."}]}
+// category and description are empty when the item couldn't be looked up.
+type bagResult struct {
+	Items []bagEntry `json:"items"`
+}
+
+type bagEntry struct {
+	Item string `json:"item"`
+	Count int `json:"count"`
+	Category string `json:"category"`
+	Description string `json:"description"`
+}
+
+// nicknameResult is the output of nickname, with an empty nickname when it was cleared: {"id": 3, "pokemon": "zubat", "nickname": "Zu"}
+type nicknameResult struct {
+	ID int `json:"id"`
+	Pokemon string `json:"pokemon"`
+	Nickname string `json:"nickname"`
+}
+
+// savedResult is the output of save: {"saved_to": "/home/ash/.local/share/pokedexcli/save.json"}
+type savedResult struct {
+	SavedTo string `json:"saved_to"`
+}
+
+// loadedResult is the output of load: {"loaded_from": "save.json", "pokemon_caught": 12}
+type loadedResult struct {
+	LoadedFrom string `json:"loaded_from"`
+	PokemonCaught int `json:"pokemon_caught"`
+}
+
+// cacheResult is the output of cache: {"in_memory": {"locations": {...}, "pokemon": {...}}, "on_disk": {...}}
+type cacheResult struct {
+	InMemory struct {
+		Locations cacheStats `json:"locations"`
+		Pokemon cacheStats `json:"pokemon"`
+		Items cacheStats `json:"items"`
+	} `json:"in_memory"`
+	OnDisk diskCacheStats `json:"on_disk"`
+}
+
+type cacheStats struct {
+	Entries int `json:"entries"`
+	Bytes int64 `json:"bytes"`
+	Hits uint64 `json:"hits"`
+	Misses uint64 `json:"misses"`
+	Evictions uint64 `json:"evictions"`
+	Expirations uint64 `json:"expirations"`
+}
+
+// diskCacheStats leaves out everything but enabled when the disk cache is off, and has an error when it couldn't be read
+type diskCacheStats struct {
+	IsEnabled bool `json:"enabled"`
+	Dir string `json:"directory,omitempty"`
+	Responses int `json:"responses"`
+	Bytes int64 `json:"bytes"`
+	Error string `json:"error,omitempty"`
+}
+
+// purgeResult is the output of cache purge: {"removed": 240}
+type purgeResult struct {
+	Removed int `json:"removed"`
+}
+
+// seedResult is the output of seed: {"seed": 1697712345}
+type seedResult struct {
+	Seed int64 `json:"seed"`
+}
+
+// versionResult is the output of version: {"version": "diamond"}, or "all" when every version is played
+type versionResult struct {
+	Version string `json:"version"`
+}
+
+// formatResult is the output of format: {"format": "json"}
+type formatResult struct {
+	Format outputFormat `json:"format"`
+}
+
+/*-------------------------------------------------------------------------------------------------------------------------------------------------------------------*/
+
+func (r helpResult) writeText(w io.Writer) {
+	fmt.Fprint(w, "Usage:")
+	for _, c := range r.Commands {
+		synopsis := c.Name
+		if c.Args != "" {
+			synopsis += " " + c.Args
+		}
+
+		fmt.Fprint(w, "\n\t" + synopsis + ": " + c.Description)
+		if len(c.Aliases) > 0 {
+			fmt.Fprint(w, " (also " + strings.Join(c.Aliases, ", ") + ")")
+		}
+	}
+
+	fmt.Fprintln(w)
+	return
+}
+
+func (r locationPageResult) writeText(w io.Writer) {
+	for _, location := range r.Locations {
+		fmt.Fprintln(w, location)
+	}
+
+	if r.Version == "" {
+		fmt.Fprintf(w, "\nPage %d of %d\n", r.Page, r.TotalPages)
+		return
+	}
+
+	if len(r.Locations) == 0 {
+		fmt.Fprintf(w, "None of the areas on this page are in %s\n", displayName(r.Version))
+	}
+	fmt.Fprintf(w, "\nPage %d of %d, areas in %s\n", r.Page, r.TotalPages, displayName(r.Version))
+	return
+}
+
+func (r areaResult) writeText(w io.Writer) {
+	for _, name := range r.Pokemon {
+		fmt.Fprintln(w, "  -", name)
+		for _, encounter := range r.Encounters {
+			if encounter.Pokemon != name {
+				continue
+			}
+
+			levels := fmt.Sprintf("level %d", encounter.MinLevel)
+			if encounter.MaxLevel != encounter.MinLevel {
+				levels = fmt.Sprintf("levels %d-%d", encounter.MinLevel, encounter.MaxLevel)
+			}
+			fmt.Fprintf(w, "      %s: %d%%, %s\n", encounter.Method, encounter.Chance, levels)
+		}
+	}
+
+	return
+}
+
+func (r encounterResult) writeText(w io.Writer) {
+	if r.Pokemon != "" {
+		fmt.Fprintf(w, "A wild %s appeared! (level %d)\n", r.Pokemon, r.Level)
+	} else if strings.HasSuffix(r.Method, "-rod") {
+		fmt.Fprintln(w, "Not even a nibble...")
+	} else {
+		fmt.Fprintln(w, "Nothing appeared...")
+	}
+
+	return
+}
+
+func (r catchResult) writeText(w io.Writer) {
+	fmt.Fprintf(w, "Throwing a %s at %s...\n", displayName(r.Ball), r.Pokemon)
+	if r.Odds != nil {
+		fmt.Fprintf(w, "(%.1f%% chance of catching it)\n", *r.Odds * 100)
+	}
+	for shake := 0; shake < r.Shakes; shake++ {
+		fmt.Fprintln(w, "  ...the ball shakes...")
+	}
+
+	if r.IsCaught && r.IsShiny {
+		fmt.Fprintf(w, "%s was caught, and it's shiny! (ID %d)\n", r.Pokemon, r.ID)
+	} else if r.IsCaught {
+		fmt.Fprintf(w, "%s was caught! (ID %d)\n", r.Pokemon, r.ID)
+	} else {
+		fmt.Fprintln(w, r.Pokemon, "escaped!")
+	}
+	if r.Box != 0 {
+		fmt.Fprintf(w, "Your party is full, so it was sent to box %d\n", r.Box)
+	}
+	if r.BallsLeft == 1 {
+		fmt.Fprintf(w, "(1 %s left)\n", displayName(r.Ball))
+	} else {
+		fmt.Fprintf(w, "(%d %ss left)\n", r.BallsLeft, displayName(r.Ball))
+	}
+
+	return
+}
+
+func (r bagResult) writeText(w io.Writer) {
+	if len(r.Items) == 0 {
+		fmt.Fprintln(w, "Your bag is empty!")
+		return
+	}
+
+	fmt.Fprintln(w, "Your bag:")
+	for _, item := range r.Items {
+		fmt.Fprintf(w, "  - %s x%d", displayName(item.Item), item.Count)
+		if item.Description != "" {
+			fmt.Fprint(w, ": ", item.Description)
+		}
+		fmt.Fprintln(w)
+	}
+
+	return
+}
+
+func (r pokemonResult) writeText(w io.Writer) {
+	fmt.Fprintln(w, "Name:", r.Name)
+	if r.ID != 0 {
+		fmt.Fprintln(w, "ID:", r.ID)
+	}
+	if r.Nickname != "" {
+		fmt.Fprintln(w, "Nickname:", r.Nickname)
+	}
+	if r.Level != 0 {
+		fmt.Fprintln(w, "Level:", r.Level)
+	}
+	if r.Nature != "" {
+		fmt.Fprintln(w, "Nature:", displayName(r.Nature))
+	}
+	if r.Gender != "" {
+		fmt.Fprintln(w, "Gender:", r.Gender)
+	}
+	if r.IsShiny {
+		fmt.Fprintln(w, "Shiny: yes")
+	}
+	if r.CaughtAt != nil {
+		fmt.Fprintln(w, "Caught:", r.CaughtAt.Format("2006-01-02 15:04"))
+	}
+	if r.CaughtIn != "" {
+		fmt.Fprintln(w, "Caught in:", r.CaughtIn)
+	}
+	if r.CaughtWith != "" {
+		fmt.Fprintln(w, "Caught with:", displayName(r.CaughtWith))
+	}
+	fmt.Fprintln(w, "Height:", r.Height)
+	fmt.Fprintln(w, "Weight:", r.Weight)
+
+	// A pokemon's own stats come with the base stat and IV they were worked out from
+	fmt.Fprintln(w, "Stats:")
+	names := []string{"hp", "attack", "defense", "special-attack", "special-defense", "Speed"}
+	for i, base := range r.Stats.list() {
+		if r.ActualStats == nil {
+			fmt.Fprintf(w, "  -%s: %d\n", names[i], base)
+		} else {
+			fmt.Fprintf(w, "  -%s: %d (base %d, IV %d)\n", names[i], r.ActualStats.list()[i], base, r.IVs.list()[i])
+		}
+	}
+	fmt.Fprintln(w, "Types:")
+
+	for _, type_ := range r.Types {
+		fmt.Fprintln(w, "  -" + type_)
+	}
+
+	return
+}
+
+func (r partyResult) writeText(w io.Writer) {
+	if len(r.Party) == 0 {
+		fmt.Fprintln(w, "Your party is empty, catch a pokemon first!")
+		return
+	}
+
+	fmt.Fprintln(w, "Your party:")
+	for _, pokemon := range r.Party {
+		fmt.Fprintln(w, "  -", pokemon.describe())
+	}
+
+	return
+}
+
+func (r boxResult) writeText(w io.Writer) {
+	if len(r.Pokemon) == 0 {
+		fmt.Fprintf(w, "Box %d is empty\n", r.Box)
+		return
+	}
+
+	fmt.Fprintf(w, "Box %d (%d/%d):\n", r.Box, len(r.Pokemon), r.Capacity)
+	for _, pokemon := range r.Pokemon {
+		fmt.Fprintln(w, "  -", pokemon.describe())
+	}
+
+	return
+}
+
+// describe is one line about the pokemon, e.g. "3: zubat (Zu), level 5"
+func (p pokemonSummary) describe() (line string) {
+	line = fmt.Sprintf("%d: %s", p.ID, p.Species)
+	if p.Nickname != "" {
+		line += fmt.Sprintf(" (%s)", p.Nickname)
+	}
+	if p.Level != 0 {
+		line += fmt.Sprintf(", level %d", p.Level)
+	}
+	if p.IsShiny {
+		line += ", shiny"
+	}
+	return line
+}
+
+func (r depositResult) writeText(w io.Writer) {
+	fmt.Fprintf(w, "Deposited your %s (ID %d) in box %d\n", r.Pokemon, r.ID, r.Box)
+	return
+}
+
+func (r withdrawResult) writeText(w io.Writer) {
+	fmt.Fprintf(w, "Withdrew your %s (ID %d) from box %d into your party\n", r.Pokemon, r.ID, r.Box)
+	return
+}
+
+func (r swapResult) writeText(w io.Writer) {
+	fmt.Fprintf(w, "Swapped your %s (ID %d) and your %s (ID %d)\n", r.Swapped[0].Species, r.Swapped[0].ID, r.Swapped[1].Species, r.Swapped[1].ID)
+	return
+}
+
+func (r releaseResult) writeText(w io.Writer) {
+	fmt.Fprintf(w, "Released your %s (ID %d). Bye, %s!\n", r.Pokemon, r.ID, r.Pokemon)
+	return
+}
+
+func (s pokemonStats) list() (stats []int) {
+	return []int{s.HP, s.Attack, s.Defense, s.SpecialAttack, s.SpecialDefense, s.Speed}
+}
+
+func (r pokedexResult) writeText(w io.Writer) {
+	if len(r.Pokemon) == 0 {
+		fmt.Fprintln(w, "You haven't caught any pokemon yet!")
+		return
+	}
+
+	fmt.Fprintln(w, "Your Pokedex:")
+	for _, pokemon := range r.Pokemon {
+		line := "  - " + pokemon.Species
+		if pokemon.Nickname != "" {
+			line += fmt.Sprintf(" (%s)", pokemon.Nickname)
+		}
+		if pokemon.Owned > 1 {
+			line += fmt.Sprintf(" x%d", pokemon.Owned)
+		}
+		fmt.Fprintln(w, line)
+	}
+
+	return
+}
+
+func (r nicknameResult) writeText(w io.Writer) {
+	if r.Nickname == "" {
+		fmt.Fprintf(w, "Cleared the nickname of your %s (ID %d)\n", r.Pokemon, r.ID)
+	} else {
+		fmt.Fprintf(w, "Your %s (ID %d) is now called %s\n", r.Pokemon, r.ID, r.Nickname)
+	}
+
+	return
+}
+
+func (r savedResult) writeText(w io.Writer) {
+	fmt.Fprintln(w, "Saved to", r.SavedTo)
+	return
+}
+
+func (r loadedResult) writeText(w io.Writer) {
+	fmt.Fprintf(w, "Loaded %s, %d pokemon caught so far\n", r.LoadedFrom, r.PokemonCaught)
+	return
+}
+
+func (r cacheResult) writeText(w io.Writer) {
+	fmt.Fprintln(w, "In memory:")
+	r.InMemory.Locations.writeText(w, "locations")
+	r.InMemory.Pokemon.writeText(w, "pokemon")
+	r.InMemory.Items.writeText(w, "items")
+
+	fmt.Fprintln(w, "On disk:")
+	if !r.OnDisk.IsEnabled {
+		fmt.Fprintln(w, "  turned off for this session")
+		return
+	} else if r.OnDisk.Error != "" {
+		fmt.Fprintln(w, "  couldn't read it:", r.OnDisk.Error)
+		return
+	}
+
+	fmt.Fprintln(w, "  directory:", r.OnDisk.Dir)
+	fmt.Fprintln(w, "  responses cached:", r.OnDisk.Responses)
+	fmt.Fprintf(w, "  size: %.1f KiB\n", float64(r.OnDisk.Bytes) / 1024)
+	return
+}
+
+func (stats cacheStats) writeText(w io.Writer, name string) {
+	fmt.Fprintf(w, "  %s: %d entries, %.1f KiB, %d hits, %d misses, %d evicted, %d expired\n",
+		name, stats.Entries, float64(stats.Bytes) / 1024, stats.Hits, stats.Misses, stats.Evictions, stats.Expirations)
+	return
+}
+
+func (r purgeResult) writeText(w io.Writer) {
+	fmt.Fprintf(w, "Removed %d cached responses\n", r.Removed)
+	return
+}
+
+func (r seedResult) writeText(w io.Writer) {
+	fmt.Fprintln(w, "Seed:", r.Seed)
+	return
+}
+
+func (r versionResult) writeText(w io.Writer) {
+	if r.Version == allVersions {
+		fmt.Fprintln(w, "Game version: all of them")
+	} else {
+		fmt.Fprintln(w, "Game version:", displayName(r.Version))
+	}
+	return
+}
+
+func (r formatResult) writeText(w io.Writer) {
+	fmt.Fprintln(w, "Output format:", r.Format)
+	return
+}
+
+// displayName turns PokeAPI names like "great-ball" into "Great Ball"
+func displayName(name string) (display string) {
+	words := strings.Split(name, "-")
+	for i, word := range words {
+		if word != "" {
+			words[i] = strings.ToUpper(word[:1]) + word[1:]
+		}
+	}
+
+	return strings.Join(words, " ")
+}
+
+// caughtTime is nil for pokemon from saves that didn't record when they were caught
+func caughtTime(caughtAt time.Time) (when *time.Time) {
+	if caughtAt.IsZero() {
+		return nil
+	}
+
+	return &caughtAt
+}
+
+/*==================================================================================================================================*/
+
+// newPokemonResult shows one of the trainer's pokemon, or just its species when it has no ID
+func newPokemonResult(pokemon player.Pokemon, data pokecache.PokemonData) (r pokemonResult) {
+	base := game.Stats{
+		HP: data.HP,
+		Attack: data.Attack,
+		Defense: data.Defense,
+		SpecialAttack: data.SpecialAttack,
+		SpecialDefense: data.SpecialDefense,
+		Speed: data.Speed,
+	}
+	r = pokemonResult{
+		Name: pokemon.Species,
+		Nickname: pokemon.Nickname,
+		CaughtAt: caughtTime(pokemon.CaughtAt),
+		CaughtIn: pokemon.Location,
+		Height: data.Height,
+		Weight: data.Weight,
+		Stats: pokemonStats(base),
+		Types: nonNil(data.Types),
+		ID: pokemon.ID,
+		Level: pokemon.Level,
+		Nature: string(pokemon.Nature),
+		Gender: string(pokemon.Gender),
+		IsShiny: pokemon.IsShiny,
+		CaughtWith: pokemon.Ball,
+	}
+
+	if pokemon.IVs != nil && pokemon.Level > 0 {
+		ivs := pokemonStats(*pokemon.IVs)
+		actual := pokemonStats(game.ActualStats(base, *pokemon.IVs, pokemon.Level, pokemon.Nature))
+		r.IVs = &ivs
+		r.ActualStats = &actual
+	}
+	return r
+}
+
+// newAreaResult joins each pokemon's encounters with the same method across versions
+func newAreaResult(area string, encounters []pokecache.Encounter) (r areaResult) {
+	r = areaResult{Area: area, Pokemon: []string{}, Encounters: []encounterSummary{}}
+	for _, encounter := range encounters {
+		if !slices.Contains(r.Pokemon, encounter.Pokemon) {
+			r.Pokemon = append(r.Pokemon, encounter.Pokemon)
+		}
+
+		i := slices.IndexFunc(r.Encounters, func(summary encounterSummary) bool {
+			return summary.Pokemon == encounter.Pokemon && summary.Method == encounter.Method
+		})
+		if i == -1 {
+			r.Encounters = append(r.Encounters, encounterSummary{
+				Pokemon: encounter.Pokemon,
+				Method: encounter.Method,
+				MinLevel: encounter.MinLevel,
+				MaxLevel: encounter.MaxLevel,
+				Versions: []string{},
+			})
+			i = len(r.Encounters) - 1
+		}
+
+		summary := &r.Encounters[i]
+		summary.Chance = max(summary.Chance, encounter.Chance)
+		summary.MinLevel = min(summary.MinLevel, encounter.MinLevel)
+		summary.MaxLevel = max(summary.MaxLevel, encounter.MaxLevel)
+		if !slices.Contains(summary.Versions, encounter.Version) {
+			summary.Versions = append(summary.Versions, encounter.Version)
+		}
+	}
+
+	return r
+}
+
+func newPokedexResult(caughtPokemon []player.CaughtPokemon, owned []player.Pokemon) (r pokedexResult) {
+	r.Pokemon = []pokedexEntry{}
+	for _, caught := range caughtPokemon {
+		entry := pokedexEntry{
+			Species: caught.Species,
+			Nickname: caught.Nickname,
+			CaughtAt: caughtTime(caught.CaughtAt),
+			CaughtIn: caught.Location,
+		}
+		for _, pokemon := range owned {
+			if pokemon.Species == caught.Species {
+				entry.Owned++
+			}
+		}
+		r.Pokemon = append(r.Pokemon, entry)
+	}
+
+	return r
+}
+
+func newPokemonSummary(pokemon player.Pokemon) (r pokemonSummary) {
+	return pokemonSummary{ID: pokemon.ID, Species: pokemon.Species, Nickname: pokemon.Nickname, Level: pokemon.Level, IsShiny: pokemon.IsShiny}
+}
+
+func newPartyResult(party []player.Pokemon) (r partyResult) {
+	r.Party = []pokemonSummary{}
+	for _, pokemon := range party {
+		r.Party = append(r.Party, newPokemonSummary(pokemon))
+	}
+
+	return r
+}
+
+func newBoxResult(box int, pokemon []player.Pokemon) (r boxResult) {
+	r = boxResult{Box: box, Capacity: player.BoxCapacity, Pokemon: []pokemonSummary{}}
+	for _, p := range pokemon {
+		r.Pokemon = append(r.Pokemon, newPokemonSummary(p))
+	}
+
+	return r
+}
+
+func newCacheStats(stats pokecache.Stats) (r cacheStats) {
+	return cacheStats{
+		Entries: stats.Entries,
+		Bytes: stats.Bytes,
+		Hits: stats.Hits,
+		Misses: stats.Misses,
+		Evictions: stats.Evictions,
+		Expirations: stats.Expirations,
+	}
+}
+
+// nonNil makes empty lists come out as [] in JSON rather than null
+func nonNil[T any](items []T) (nonNilItems []T) {
+	if items == nil {
+		return []T{}
+	}
+
+	return items
+}
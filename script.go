@@ -0,0 +1,119 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// maxScriptDepth stops a script that runs itself, directly or not, from recursing forever
+const maxScriptDepth = 8
+
+// scriptError says which line of a script failed, so it can be found and fixed
+type scriptError struct {
+	line int
+	command string
+	err error
+}
+
+// scriptStatement is one command of a script and the line it's on
+type scriptStatement struct {
+	line int
+	command string
+}
+
+/*-------------------------------------------------------------------------------------------------------------------------------------------------------------------*/
+
+func (e *scriptError) Error() (message string) {
+	return fmt.Sprintf("Line %d (%s): %v", e.line, e.command, e.err)
+}
+
+func (e *scriptError) Unwrap() (err error) {
+	return e.err
+}
+
+// runScript runs each command in turn and stops at the first one that fails, or at "exit". Scripts run from the command
+// line record their commands in the transcript, while run only records itself.
+func (s *session) runScript(ctx context.Context, script string, isRecorded bool) (err error) {
+	if s.scriptDepth == maxScriptDepth {
+		return fmt.Errorf("Scripts can only run other scripts %d deep", maxScriptDepth)
+	}
+	s.scriptDepth++
+	defer func() { s.scriptDepth-- }()
+
+	for _, statement := range splitScript(script) {
+		if s.isExiting {
+			return nil
+		}
+		err = s.commands.run(ctx, s, statement.command)
+		if isRecorded {
+			s.record(statement.command, err)
+		}
+		if err != nil {
+			return &scriptError{line: statement.line, command: statement.command, err: err}
+		}
+	}
+
+	return nil
+}
+
+func readScript(path string) (script string, err error) {
+	contents, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("Couldn't read script: %w", err)
+	}
+
+	return string(contents), nil
+}
+
+// splitScript breaks a script into commands at newlines and at semicolons outside quotes. Blank commands and
+// comments, from a # that starts a word to the end of its line, are dropped.
+func splitScript(script string) (statements []scriptStatement) {
+	var current strings.Builder
+	var quote rune
+	isEscaped := false
+	isComment := false
+	line := 1
+
+	endStatement := func() {
+		command := strings.TrimSpace(current.String())
+		if command != "" {
+			statements = append(statements, scriptStatement{line: line, command: command})
+		}
+		current.Reset()
+		return
+	}
+
+	for _, char := range script {
+		switch {
+		case char == '\n':
+			endStatement()
+			quote, isEscaped, isComment = 0, false, false
+			line++
+			continue
+		case isComment:
+			continue
+		case isEscaped:
+			isEscaped = false
+		case char == '\\' && quote != '\'':
+			isEscaped = true
+		case quote != 0:
+			if char == quote {
+				quote = 0
+			}
+		case char == '\'' || char == '"':
+			quote = char
+		case char == ';':
+			endStatement()
+			continue
+		case char == '#' && (current.Len() == 0 || strings.HasSuffix(current.String(), " ") || strings.HasSuffix(current.String(), "\t")):
+			isComment = true
+			continue
+		}
+		current.WriteRune(char)
+	}
+	endStatement()
+
+	return statements
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestSplitScript(t *testing.T) {
+	tests := []struct {
+		name string
+		script string
+		want []scriptStatement
+	}{
+		{"empty", "", nil},
+		{"blank lines", "\n  \n\t\n", nil},
+		{"one per line", "map\nexplore area-1\n", []scriptStatement{{1, "map"}, {2, "explore area-1"}}},
+		{"semicolons", "map; map ;mapb", []scriptStatement{{1, "map"}, {1, "map"}, {1, "mapb"}}},
+		{"empty statements", ";; map ;;\n;", []scriptStatement{{1, "map"}}},
+		{"line numbers skip blanks and comments", "# setup\n\nseed 42\n\n# go\nmap; map\n", []scriptStatement{{3, "seed 42"}, {6, "map"}, {6, "map"}}},
+		{"trailing comment", "map # first page\nmapb", []scriptStatement{{1, "map"}, {2, "mapb"}}},
+		{"comment hides semicolons", "map # then; mapb\nexit", []scriptStatement{{1, "map"}, {2, "exit"}}},
+		{"comment after tab", "map\t# first page", []scriptStatement{{1, "map"}}},
+		{"hash inside a word", "nickname 1 no#1; map", []scriptStatement{{1, "nickname 1 no#1"}, {1, "map"}}},
+		{"hash in quotes", `nickname 1 "# one"`, []scriptStatement{{1, `nickname 1 "# one"`}}},
+		{"semicolon in double quotes", `nickname 1 "a;b"; map`, []scriptStatement{{1, `nickname 1 "a;b"`}, {1, "map"}}},
+		{"semicolon in single quotes", `nickname 1 'a;b'; map`, []scriptStatement{{1, `nickname 1 'a;b'`}, {1, "map"}}},
+		{"escaped semicolon", `nickname 1 a\;b; map`, []scriptStatement{{1, `nickname 1 a\;b`}, {1, "map"}}},
+		{"escaped hash", `nickname 1 \#1`, []scriptStatement{{1, `nickname 1 \#1`}}},
+		{"unterminated quote ends with its line", "nickname 1 \"oops; map\nmapb", []scriptStatement{{1, `nickname 1 "oops; map`}, {2, "mapb"}}},
+		{"windows line endings", "map\r\nmapb\r\n", []scriptStatement{{1, "map"}, {2, "mapb"}}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := splitScript(test.script); !slices.Equal(got, test.want) {
+				t.Errorf("splitScript(%q) = %+v, want %+v", test.script, got, test.want)
+			}
+		})
+	}
+}
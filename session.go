@@ -0,0 +1,169 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"os"
+	"strings"
+	"time"
+	"github.com/CRowland4/pokedexcli/internal/pokeapi"
+	"github.com/CRowland4/pokedexcli/internal/pokecache"
+	"github.com/CRowland4/pokedexcli/internal/player"
+	"github.com/CRowland4/pokedexcli/internal/game"
+)
+
+// session is everything the REPL commands share between them
+type session struct {
+	client *pokeapi.Client
+	cache pokecache.Cache
+	diskCache *pokecache.DiskCache
+	locationCacher func(context.Context, *pokecache.Cache, string) (pokeapi.LocationPage, error)
+	trainer *player.Trainer
+	savePath string
+	commands *commandRegistry
+	format outputFormat
+	renderer renderer
+	seed int64
+	random *rand.Rand  // Every game mechanic rolls with this, so a seed replays a whole session
+	transcript *os.File  // Every command run, for replaying as a script, or nil
+
+	currentLocations []string
+	currentArea string
+	wildPokemon *game.WildPokemon  // The pokemon that can be caught right now, or nil
+	scriptDepth int  // How many scripts deep the running command is
+	isExiting bool
+}
+
+// allVersions is the game version name for playing every version at once
+const allVersions = "all"
+
+/*-------------------------------------------------------------------------------------------------------------------------------------------------------------------*/
+
+func (s *session) complete(words []string, partial string) (candidates []string) {
+	return s.commands.completions(s, words, partial)
+}
+
+// show writes a command's result to stdout in the session's output format
+func (s *session) show(r result) (err error) {
+	if err = s.renderer.render(os.Stdout, r); err != nil {
+		return fmt.Errorf("Couldn't show the result as %s: %w", s.format, err)
+	}
+
+	return nil
+}
+
+func (s *session) setFormat(format outputFormat) {
+	s.format = format
+	s.renderer = newRenderer(format)
+	return
+}
+
+// setSeed restarts the session's random numbers from seed and records it in the save file
+func (s *session) setSeed(seed int64) {
+	s.seed = seed
+	s.random = rand.New(rand.NewSource(seed))
+	s.trainer.SetSeed(seed)
+	return
+}
+
+// startTranscript appends to the transcript at path, starting with the seed so that running the transcript as a script
+// replays the session
+func (s *session) startTranscript(path string) (err error) {
+	s.transcript, err = os.OpenFile(path, os.O_WRONLY | os.O_CREATE | os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(s.transcript, "# pokedexcli session started %s\nseed %d\n", time.Now().Format(time.RFC3339), s.seed)
+	if version := s.trainer.GameVersion(); err == nil && version != "" {
+		_, err = fmt.Fprintf(s.transcript, "version %s\n", version)
+	}
+	return err
+}
+
+// setGameVersion plays only the areas and encounters of the named game version from now on, after checking the PokeAPI
+// knows it. allVersions goes back to playing every version.
+func (s *session) setGameVersion(ctx context.Context, name string) (err error) {
+	name = strings.ToLower(name)
+	if name == allVersions {
+		s.trainer.SetGameVersion("")
+		return nil
+	}
+
+	version, err := s.client.GetVersion(ctx, name)
+	var notFoundErr *pokeapi.NotFoundError
+	if errors.As(err, &notFoundErr) {
+		return fmt.Errorf("There's no game version called %s", name)
+	} else if err != nil {
+		return err
+	}
+
+	s.trainer.SetGameVersion(version.Name)
+	return nil
+}
+
+// inGameVersion keeps the encounters of the trainer's game version, or all of them when every version is played
+func (s *session) inGameVersion(encounters []pokecache.Encounter) (kept []pokecache.Encounter) {
+	version := s.trainer.GameVersion()
+	for _, encounter := range encounters {
+		if version == "" || encounter.Version == version {
+			kept = append(kept, encounter)
+		}
+	}
+
+	return kept
+}
+
+// methodRatesInGameVersion keeps the method rates of the trainer's game version, or all of them when every version is played
+func (s *session) methodRatesInGameVersion(methodRates []pokecache.MethodRate) (kept []pokecache.MethodRate) {
+	version := s.trainer.GameVersion()
+	for _, methodRate := range methodRates {
+		if version == "" || methodRate.Version == version {
+			kept = append(kept, methodRate)
+		}
+	}
+
+	return kept
+}
+
+// locationsInGameVersion keeps the cached location areas that have any encounters in the trainer's game version
+func (s *session) locationsInGameVersion(locations []string) (kept []string) {
+	kept = []string{}
+	for _, location := range locations {
+		entry, isFound := s.cache.GetLocationByName(location)
+		if s.trainer.GameVersion() == "" || (isFound && len(s.inGameVersion(entry.Encounters)) > 0) {
+			kept = append(kept, location)
+		}
+	}
+
+	return kept
+}
+
+// record adds a command to the transcript. Commands that failed are commented out, so the transcript replays cleanly.
+func (s *session) record(line string, commandErr error) {
+	if s.transcript == nil || strings.TrimSpace(line) == "" {
+		return
+	}
+
+	if commandErr != nil {
+		line = "# failed: " + line
+	}
+	if _, err := fmt.Fprintln(s.transcript, line); err != nil {
+		fmt.Fprintln(os.Stderr, "Couldn't write to the transcript, no more commands will be recorded:", err)
+		s.transcript.Close()
+		s.transcript = nil
+	}
+
+	return
+}
+
+// autosave is called after every change to the trainer. Failing to save is reported but doesn't undo the change.
+func (s *session) autosave() {
+	if err := player.Save(s.savePath, s.trainer); err != nil {
+		fmt.Fprintln(os.Stderr, "Couldn't save your Pokedex:", err)
+	}
+
+	return
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"github.com/CRowland4/pokedexcli/internal/player"
+)
+
+// registerSubcommands sets up the one-shot commands run straight from the shell, like "pokedexcli pokemon pikachu",
+// which print their result once and exit instead of starting the prompt
+func registerSubcommands(r *commandRegistry) {
+	r.register(command{name: "pokemon", args: "<pokemon>", description: "Show a pokemon's stats and types, and when you caught it", minArgs: 1, maxArgs: 1, handler: subcommandPokemon})
+	r.register(command{name: "area", args: "<area>", description: "List the pokemon found in a location area", minArgs: 1, maxArgs: 1, handler: subcommandArea})
+	r.register(command{name: "locations", args: "[--page <n>]", description: "List one page of location areas, the first unless a page is given", maxArgs: -1, handler: subcommandLocations})
+	r.register(command{name: "dex", args: "list", description: "List the pokemon you have caught", minArgs: 1, maxArgs: 1, handler: subcommandDex})
+	return
+}
+
+// runSubcommand exits with 2 for a subcommand used wrongly and 1 for one that failed
+func runSubcommand(s *session, interrupts *interruptHandler, subcommands *commandRegistry, words []string) (exitCode int) {
+	ctx, done := interrupts.commandContext()
+	err := subcommands.runWords(ctx, s, words)
+	done()
+
+	var usageErr *usageError
+	printError(err)
+	if errors.As(err, &usageErr) {
+		return 2
+	} else if err != nil {
+		return 1
+	}
+
+	return 0
+}
+
+/*-------------------------------------------------------------------------------------------------------------------------------------------------------------------*/
+
+func subcommandPokemon(ctx context.Context, s *session, args []string) (err error) {
+	species := args[0]
+	if err = s.client.CachePokemon(ctx, &s.cache, species); err != nil {
+		return err
+	}
+
+	// The species as the pokedex knows it, not any one of the trainer's pokemon
+	data, _ := s.cache.GetPokemon(species)
+	entry, _ := s.trainer.GetDexEntry(species)
+	return s.show(newPokemonResult(player.Pokemon{Species: species, CaughtAt: entry.CaughtAt, Location: entry.Location}, data))
+}
+
+func subcommandArea(ctx context.Context, s *session, args []string) (err error) {
+	area := args[0]
+	if err = s.client.CacheLocationByName(ctx, &s.cache, area); err != nil {
+		return err
+	}
+
+	entry, _ := s.cache.GetLocationByName(area)
+	result := newAreaResult(area, s.inGameVersion(entry.Encounters))
+	result.Version = s.trainer.GameVersion()
+	return s.show(result)
+}
+
+func subcommandLocations(ctx context.Context, s *session, args []string) (err error) {
+	flags := flag.NewFlagSet("locations", flag.ContinueOnError)
+	pageNumber := flags.Int("page", 1, "page of location areas to show")
+	positional, err := parseFlags(flags, args)
+	if err != nil {
+		return err
+	} else if len(positional) > 0 {
+		return &usageError{problem: fmt.Sprintf("%q isn't a flag", positional[0])}
+	}
+
+	page, err := s.client.LocationPage(ctx, &s.cache, *pageNumber)
+	if len(page.Locations) > 0 {
+		locations := s.locationsInGameVersion(page.Locations)
+		if showErr := s.show(locationPageResult{Page: page.Number, TotalPages: page.Total, Locations: locations, Version: s.trainer.GameVersion()}); showErr != nil {
+			return showErr
+		}
+	}
+
+	return err
+}
+
+func subcommandDex(ctx context.Context, s *session, args []string) (err error) {
+	if args[0] != "list" {
+		return &usageError{problem: fmt.Sprintf("There is no dex %s", args[0])}
+	}
+
+	return commandPokedex(ctx, s, nil)
+}